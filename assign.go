@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// dynamicAssign lets ants change paths on the way (--dynamic-assign)
+var dynamicAssign bool
+
+// schedule assigns ants to paths with ScheduleAnts or, under
+// --dynamic-assign, with scheduleDynamic. The dynamic plan is only kept when
+// it is shorter, and the turns it saved are reported on stderr.
+func schedule(g *Graph, paths [][]int, ants int) [][]Move {
+	static := ScheduleAnts(paths, ants)
+	if !dynamicAssign {
+		return static
+	}
+
+	dynamic := scheduleDynamic(g, paths, ants)
+	if len(dynamic) >= len(static) {
+		fmt.Fprintf(os.Stderr, "dynamic-assign: no turn saved over static assignment (%d turns)\n", len(static))
+		return static
+	}
+	fmt.Fprintf(os.Stderr, "dynamic-assign: %d turns, %d fewer than static assignment\n", len(dynamic), len(static)-len(dynamic))
+	return dynamic
+}
+
+// scheduleDynamic is ScheduleAnts without binding an ant to one path. Each
+// ant in turn takes the fastest route through the rooms and tunnels of all
+// paths given the reservations of the ants before it, so it can wait for a
+// room to clear or switch to another path where two paths share a room.
+func scheduleDynamic(g *Graph, paths [][]int, ants int) [][]Move {
+	rooms, shortest := 0, paths[0]
+	for _, path := range paths {
+		for _, room := range path {
+			rooms = max(rooms, room+1)
+		}
+		if len(path) < len(shortest) {
+			shortest = path
+		}
+	}
+	start, end := shortest[0], shortest[len(shortest)-1]
+
+	// The tunnels used by any path, each listed once per direction it
+	// leads in
+	routes := make([][]int, rooms)
+	seen := make(map[[2]int]bool)
+	for _, path := range paths {
+		for i := 1; i < len(path); i++ {
+			for _, tunnel := range [][2]int{{path[i-1], path[i]}, {path[i], path[i-1]}} {
+				if !seen[tunnel] && g.leads(tunnel[0], tunnel[1]) {
+					seen[tunnel] = true
+					routes[tunnel[0]] = append(routes[tunnel[0]], tunnel[1])
+				}
+			}
+		}
+	}
+
+	table := closedSlots.clone()
+	exits := newExitCounter(end)
+	spawns := newSpawnCounter(start)
+	zones := newZoneCounter()
+	trajectories := make([][]int, ants)
+	last := 0 // arrival of the latest ant so far, or the last closed turn
+	for s := range table {
+		last = max(last, s.turn)
+	}
+	for ant := range trajectories {
+		// Once every earlier ant has arrived and the rules close no more
+		// rooms, the shortest path and the exit are free, so there is
+		// always a route within this horizon
+		tr := constrainedPath(routes, start, end, table, last+len(shortest)-1)
+		for t := 1; t < len(tr); t++ {
+			if tr[t] != start && tr[t] != end {
+				table[roomSlot(tr[t], t)] = true
+				zones.occupy(table, tr[t], t)
+			}
+			if tr[t] != tr[t-1] {
+				table[tunnelSlot(tr[t-1], tr[t], t)] = true
+			}
+		}
+		exits.arrive(table, len(tr)-1)
+		for t := 1; t < len(tr); t++ {
+			if tr[t] != start {
+				spawns.leave(table, t)
+				break
+			}
+		}
+		trajectories[ant] = tr
+		last = max(last, len(tr)-1)
+	}
+	return turnsFromTrajectories(trajectories)
+}
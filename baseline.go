@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+)
+
+// baselineFile holds the turn counts of the benchmark maps; TestBaselines
+// fails when a change makes any of them worse
+const baselineFile = "testdata/bench/baselines.json"
+
+// baselines maps "<map>/<solver>" to the number of turns of the plan
+type baselines map[string]int
+
+// measureTurns solves c with every solver and records the turns under
+// name. Solvers that refuse the map because of their limits are left out.
+func (b baselines) measureTurns(name string, c *colony.Colony) error {
+	for _, solver := range sortedSolvers() {
+		solution, err := solveColony(c, []chainStage{{name: solver}})
+		if errors.Is(err, ErrLimitExceeded) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s/%s: %w", name, solver, err)
+		}
+		b[name+"/"+solver] = len(solution.Turns)
+	}
+	return nil
+}
+
+// regressions lists the entries of measured that take more turns than the
+// baseline, and those of the baseline that could not be measured anymore
+func (b baselines) regressions(measured baselines) []string {
+	var worse []string
+	for key, turns := range b {
+		now, ok := measured[key]
+		switch {
+		case !ok:
+			worse = append(worse, fmt.Sprintf("%s: no plan anymore (baseline %d turns)", key, turns))
+		case now > turns:
+			worse = append(worse, fmt.Sprintf("%s: %d turns, baseline %d", key, now, turns))
+		}
+	}
+	sort.Strings(worse)
+	return worse
+}
+
+func loadBaselines(file string) (baselines, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var b baselines
+	return b, json.Unmarshal(data, &b)
+}
+
+func (b baselines) save(file string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
+
+// runBaseline implements "lem-in baseline": it solves the maps with every
+// solver and compares the turns with the baseline file, or rewrites the
+// file with --update. Maps are keyed by their name without extension.
+func runBaseline(args []string) int {
+	flags := flag.NewFlagSet("baseline", flag.ExitOnError)
+	file := flags.String("file", baselineFile, "baseline file")
+	update := flags.Bool("update", false, "record the current turn counts instead of checking them")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in baseline [--file baselines.json] [--update] <map>...")
+		return 2
+	}
+
+	measured := make(baselines)
+	names := make(map[string]bool)
+	for _, path := range flags.Args() {
+		c, err := parser.ParseInput(path, parser.Strict01Edu)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			return 1
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		names[name] = true
+		if err := measured.measureTurns(name, c); err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+			return 1
+		}
+	}
+
+	if *update {
+		if err := measured.save(*file); err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+			return 1
+		}
+		fmt.Printf("recorded %d turn counts in %s\n", len(measured), *file)
+		return 0
+	}
+
+	b, err := loadBaselines(*file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	// Only the maps given are checked
+	for key := range b {
+		if name, _, _ := strings.Cut(key, "/"); !names[name] {
+			delete(b, key)
+		}
+	}
+	worse := b.regressions(measured)
+	for _, line := range worse {
+		fmt.Println(line)
+	}
+	if len(worse) > 0 {
+		return 1
+	}
+	fmt.Println("no regressions")
+	return 0
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"lem2/pkg/parser"
+)
+
+var updateBaselines = flag.Bool("update-baselines", false, "rewrite "+baselineFile+" with the current turn counts")
+
+// TestBaselines fails when a solver needs more turns on a benchmark map
+// than recorded in the baseline file. After an intended change, rerun with
+// -update-baselines (or use lem-in baseline --update) and commit the file.
+func TestBaselines(t *testing.T) {
+	measured := make(baselines)
+	for _, size := range benchSizes {
+		c, err := parser.ParseLines(benchLines(t, size), parser.Strict01Edu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := measured.measureTurns(size, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if *updateBaselines {
+		if err := measured.save(baselineFile); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	b, err := loadBaselines(baselineFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, regression := range b.regressions(measured) {
+		t.Error(regression)
+	}
+	for key, turns := range measured {
+		if baseline, ok := b[key]; ok && turns < baseline {
+			t.Logf("%s: %d turns, better than the baseline %d", key, turns, baseline)
+		}
+	}
+}
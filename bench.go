@@ -0,0 +1,147 @@
+package main
+
+import (
+	"embed"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+)
+
+// The benchmark maps are committed so results stay comparable between
+// commits (e.g. with benchstat). medium.map and large.map were produced by
+// lem-in generate; the options are recorded in their header comments.
+// They are also the corpus of "lem-in bench" when no directory is given.
+//
+//go:embed testdata/bench/*.map
+var benchFiles embed.FS
+
+// benchResult is a row of the "lem-in bench" table
+type benchResult struct {
+	name         string
+	rooms, ants  int
+	turns        int
+	stage        string
+	parse, solve time.Duration // fastest of the runs
+	err          error
+}
+
+// runBench implements "lem-in bench [dir]": it parses and solves every
+// .map file of dir, or the embedded benchmark maps without one, --runs
+// times and prints a table of the turns and the fastest parse and solve
+// times of every map, so regressions show without an external harness.
+// The exit status is 1 when a map cannot be solved.
+func runBench(args []string) int {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	algo := flags.String("algo", "dfs", "scheduling algorithm: "+solverNames())
+	chain := flags.String("chain", "", "fallback chain of algorithms with time budgets, e.g. exact@5s,cbs@5s,dfs")
+	runs := flags.Int("runs", 3, "times every map is parsed and solved")
+	profileName := flags.String("profile", "strict", "map format profile: "+strings.Join(parser.ProfileNames(), ", "))
+	flags.Parse(args)
+	if flags.NArg() > 1 || *runs <= 0 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in bench [--algo name | --chain stages] [--runs n] [--profile name] [dir]")
+		return 2
+	}
+	profile, err := parser.LookupProfile(*profileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 2
+	}
+	stages, err := solverStages(*algo, *chain)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 2
+	}
+
+	var fsys fs.FS = benchFiles
+	pattern := "testdata/bench/*.map"
+	if flags.NArg() == 1 {
+		fsys, pattern = os.DirFS(flags.Arg(0)), "*.map"
+	}
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil || len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "ERROR: no .map files to run")
+		return 1
+	}
+	sort.Strings(files)
+
+	failed := false
+	results := make([]benchResult, len(files))
+	for i, file := range files {
+		results[i] = benchMap(fsys, file, profile, stages, *runs)
+		failed = failed || results[i].err != nil
+	}
+	writeBenchTable(os.Stdout, results)
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// benchMap parses and solves a map runs times
+func benchMap(fsys fs.FS, file string, profile parser.Profile, stages []chainStage, runs int) benchResult {
+	result := benchResult{name: strings.TrimSuffix(filepath.Base(file), ".map")}
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	for run := 0; run < runs; run++ {
+		started := time.Now()
+		var c *colony.Colony
+		c, result.err = parser.ParseBytes(data, profile)
+		parsed := time.Now()
+		if result.err != nil {
+			return result
+		}
+		solution, err := solveColony(c, stages)
+		solved := time.Now()
+		if err != nil {
+			result.err = err
+			return result
+		}
+		if run == 0 || parsed.Sub(started) < result.parse {
+			result.parse = parsed.Sub(started)
+		}
+		if run == 0 || solved.Sub(parsed) < result.solve {
+			result.solve = solved.Sub(parsed)
+		}
+		result.rooms, result.ants = len(c.Rooms), c.Ants
+		result.turns, result.stage = len(solution.Turns), solution.Stage
+	}
+	return result
+}
+
+func writeBenchTable(w io.Writer, results []benchResult) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "map\trooms\tants\tturns\tsolver\tparse\tsolve")
+	var turns int
+	var parse, solve time.Duration
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(table, "%s\t\t\t\tfailed\t\t\n", r.name)
+			continue
+		}
+		fmt.Fprintf(table, "%s\t%d\t%d\t%d\t%s\t%v\t%v\n", r.name, r.rooms, r.ants, r.turns, r.stage,
+			r.parse.Round(time.Microsecond), r.solve.Round(time.Microsecond))
+		turns += r.turns
+		parse += r.parse
+		solve += r.solve
+	}
+	fmt.Fprintf(table, "total\t\t\t%d\t\t%v\t%v\n", turns, parse.Round(time.Microsecond), solve.Round(time.Microsecond))
+	table.Flush()
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(w, "%s: %v\n", r.name, r.err)
+		}
+	}
+}
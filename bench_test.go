@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+)
+
+// benchSizes are the embedded benchmark maps, see benchFiles
+var benchSizes = []string{"small", "medium", "large"}
+
+// benchLines returns the lines of an embedded benchmark map
+func benchLines(tb testing.TB, size string) []string {
+	data, err := benchFiles.ReadFile("testdata/bench/" + size + ".map")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+}
+
+// benchColony parses an embedded benchmark map and builds its graph
+func benchColony(b *testing.B, size string) (*colony.Colony, *Graph, int, int) {
+	c, err := parser.ParseLines(benchLines(b, size), parser.Strict01Edu)
+	if err != nil {
+		b.Fatal(err)
+	}
+	g := graphFromColony(c)
+	start, _ := g.ID(c.Start)
+	end, _ := g.ID(c.End)
+	return c, g, start, end
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(size, func(b *testing.B) {
+			lines := benchLines(b, size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.ParseLines(lines, parser.Strict01Edu); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkPathfind runs every solver on every map. Solvers that refuse a
+// map because of their size limits are skipped.
+func BenchmarkPathfind(b *testing.B) {
+	names := sortedSolvers()
+	for _, size := range benchSizes {
+		c, g, start, end := benchColony(b, size)
+		for _, name := range names {
+			solve := solvers[name]
+			b.Run(size+"/"+name, func(b *testing.B) {
+				ctx := context.Background()
+				if _, err := solve(ctx, g, start, end, c.Ants); err != nil {
+					if errors.Is(err, ErrLimitExceeded) {
+						b.Skip(err)
+					}
+					b.Fatal(err)
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					solve(ctx, g, start, end, c.Ants)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkPlan measures assigning the ants to paths that are already known
+func BenchmarkPlan(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(size, func(b *testing.B) {
+			c, _, _, _ := benchColony(b, size)
+			ps, err := FindPathSet(c)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				AssignAnts(ps, c.Ants)
+			}
+		})
+	}
+}
+
+// BenchmarkSimulate measures replaying a plan turn by turn and checking
+// every move
+func BenchmarkSimulate(b *testing.B) {
+	for _, size := range benchSizes {
+		b.Run(size, func(b *testing.B) {
+			c, g, start, end := benchColony(b, size)
+			paths, err := g.FindAllPaths(context.Background(), start, end)
+			if err != nil {
+				b.Fatal(err)
+			}
+			turns := ScheduleAnts(paths, c.Ants)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := validateSchedule(g, start, end, c.Ants, turns); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkHeuristics sweeps the path count of the bounded solver and
+// reports the turns of every setting next to its time, e.g.
+//
+//	go test -run '^$' -bench Heuristics
+func BenchmarkHeuristics(b *testing.B) {
+	defer func(saved heuristicProfile) { heuristics = saved }(heuristics)
+	for _, size := range benchSizes[:2] {
+		c, g, start, end := benchColony(b, size)
+		for _, paths := range []int{1, 10, 50, 200} {
+			b.Run(fmt.Sprintf("%s/bounded_paths=%d", size, paths), func(b *testing.B) {
+				heuristics.BoundedPaths = paths
+				var turns [][]Move
+				for i := 0; i < b.N; i++ {
+					var err error
+					if turns, err = solveBounded(context.Background(), g, start, end, c.Ants); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(len(turns)), "turns")
+			})
+		}
+	}
+}
+
+// superposition returns a square grid of rooms from one corner to the
+// opposite one: its paths overlap everywhere, like the superposition maps
+// of the original lem-in examples but large enough to time the layers on
+// room IDs
+func superposition(size, ants int) *colony.Colony {
+	c := colony.New()
+	c.Ants, c.Start, c.End = ants, "0-0", fmt.Sprintf("%d-%d", size-1, size-1)
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			name := fmt.Sprintf("%d-%d", x, y)
+			c.Rooms[name] = &colony.Room{Name: name, X: x, Y: y}
+			if x > 0 {
+				c.AddTunnel(colony.Tunnel{From: fmt.Sprintf("%d-%d", x-1, y), To: name})
+			}
+			if y > 0 {
+				c.AddTunnel(colony.Tunnel{From: fmt.Sprintf("%d-%d", x, y-1), To: name})
+			}
+		}
+	}
+	return c
+}
+
+// BenchmarkSuperposition times every layer of a solve on a large
+// superposition map: building the graph of room IDs from the names, the
+// max-flow path search, the assignment of ants, the check of the plan and
+// the resolution of room names for the output
+func BenchmarkSuperposition(b *testing.B) {
+	c := superposition(30, 200)
+	g := graphFromColony(c)
+	start, _ := g.ID(c.Start)
+	end, _ := g.ID(c.End)
+	ctx := context.Background()
+	paths, err := g.disjointPaths(ctx, start, end, c.Ants)
+	if err != nil {
+		b.Fatal(err)
+	}
+	turns := ScheduleAnts(paths, c.Ants)
+	solution := &Solution{Graph: g, Start: start, End: end, Turns: turns}
+
+	b.Run("graph", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			graphFromColony(c)
+		}
+	})
+	b.Run("paths", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := g.disjointPaths(ctx, start, end, c.Ants); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("plan", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ScheduleAnts(paths, c.Ants)
+		}
+	})
+	b.Run("simulate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := validateSchedule(g, start, end, c.Ants, turns); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("names", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			solution.roomNames()
+		}
+	})
+}
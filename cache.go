@@ -0,0 +1,111 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// topologyKey identifies the rooms, tunnels, start and end of a graph,
+// leaving out the number of ants
+type topologyKey [sha256.Size]byte
+
+type planKey struct {
+	topology topologyKey
+	ants     int
+	stages   string
+}
+
+type pathKey struct {
+	topology topologyKey
+	limit    int
+}
+
+// planCache remembers plans and path sets across solves in long-running
+// commands. A plan depends on the topology and the number of ants, but the
+// paths found by the DFS only depend on the topology, so a map whose ant
+// count changed reuses them and only the assignment of ants is redone.
+type planCache struct {
+	mu    sync.Mutex
+	plans map[planKey]cachedPlan
+	paths map[pathKey][][]int
+}
+
+type cachedPlan struct {
+	turns [][]Move
+	stage string
+}
+
+// cache is nil unless the command keeps plans between solves (watch)
+var cache *planCache
+
+func newPlanCache() *planCache {
+	return &planCache{plans: make(map[planKey]cachedPlan), paths: make(map[pathKey][][]int)}
+}
+
+// topology hashes the graph with its start and end. Room IDs follow the
+// sorted room names (see graphFromColony), so the same map parsed twice
+// gets the same key and cached room IDs stay valid.
+func (g *Graph) topology(start, end int) topologyKey {
+	h := sha256.New()
+	for _, name := range g.names {
+		fmt.Fprintf(h, "%d:%s", len(name), name)
+	}
+	var buf [8]byte
+	for _, tunnel := range append(g.tunnels(), [2]int{start, end}) {
+		for _, room := range tunnel {
+			binary.LittleEndian.PutUint64(buf[:], uint64(room))
+			h.Write(buf[:])
+		}
+	}
+	if g.oneWay != nil {
+		// The direction of every tunnel: 0 both ways, 1 forward, 2 backward
+		for _, tunnel := range g.tunnels() {
+			switch {
+			case g.oneWay[tunnel]:
+				h.Write([]byte{1})
+			case g.oneWay[[2]int{tunnel[1], tunnel[0]}]:
+				h.Write([]byte{2})
+			default:
+				h.Write([]byte{0})
+			}
+		}
+	}
+	var key topologyKey
+	h.Sum(key[:0])
+	return key
+}
+
+// stagesKey describes a chain, and the heuristics and objective it runs
+// with, for plan keys
+func stagesKey(stages []chainStage) string {
+	return fmt.Sprint(stages, heuristics, objective, turnBudget)
+}
+
+func (c *planCache) plan(key planKey) (cachedPlan, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	plan, ok := c.plans[key]
+	return plan, ok
+}
+
+func (c *planCache) storePlan(key planKey, plan cachedPlan) {
+	c.mu.Lock()
+	c.plans[key] = plan
+	c.mu.Unlock()
+}
+
+// pathSet returns a copy of the cached paths, since schedulers reorder them
+func (c *planCache) pathSet(key pathKey) ([][]int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	paths, ok := c.paths[key]
+	return append([][]int(nil), paths...), ok
+}
+
+func (c *planCache) storePathSet(key pathKey, paths [][]int) {
+	c.mu.Lock()
+	c.paths[key] = append([][]int(nil), paths...)
+	c.mu.Unlock()
+}
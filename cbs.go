@@ -0,0 +1,273 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+
+	"lem2/pkg/pathfinder"
+)
+
+// Conflict-based search explodes quickly, so it is only offered as an
+// experimental reference solver for small maps.
+const (
+	maxCBSAnts  = 8
+	maxCBSNodes = 20000
+)
+
+var errCBSLimit error = pathfinder.LimitError("map too large for the CBS solver")
+
+// constraint forbids one ant from occupying a room or tunnel in a turn
+type constraint struct {
+	ant  int
+	slot slot
+}
+
+// cbsNode is a node of the constraint tree
+type cbsNode struct {
+	constraints []constraint
+	paths       [][]int // room of every ant per turn until it reaches the end
+	cost        int     // sum of the arrival turns of all ants
+	conflicts   int     // colliding pairs, used to break ties between equal costs
+}
+
+type cbsQueue []*cbsNode
+
+func (q cbsQueue) Len() int { return len(q) }
+func (q cbsQueue) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+	return q[i].conflicts < q[j].conflicts
+}
+func (q cbsQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *cbsQueue) Push(x interface{}) { *q = append(*q, x.(*cbsNode)) }
+func (q *cbsQueue) Pop() interface{} {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
+
+// CBSSchedule treats every ant as an agent with its own shortest path and
+// resolves collisions by branching on constraints (conflict-based search).
+// The result minimizes the sum of arrival turns, which makes it a reference
+// for schedulers that assume disjoint paths.
+func (g *Graph) CBSSchedule(ctx context.Context, start, end, ants int) ([][]Move, error) {
+	if ants <= 0 || start == end {
+		return nil, nil
+	}
+	if ants > maxCBSAnts {
+		return nil, errCBSLimit
+	}
+	if exitCapacity > 0 {
+		return nil, errExitCapacity
+	}
+	if spawnRate > 0 {
+		return nil, errSpawnRate
+	}
+	if closedSlots != nil {
+		return nil, errRules
+	}
+	if zoneLimits != nil {
+		return nil, errZoneCapacity
+	}
+
+	routes := g.routes(start, end)
+	root := &cbsNode{paths: make([][]int, ants)}
+	for ant := range root.paths {
+		path := constrainedPath(routes, start, end, nil, g.cbsHorizon(ants, 0))
+		if path == nil {
+			return nil, ErrNoPath
+		}
+		root.paths[ant] = path
+		root.cost += len(path) - 1
+	}
+	root.conflicts = countConflicts(root.paths, start, end)
+
+	queue := &cbsQueue{root}
+	for expanded := 0; queue.Len() > 0; expanded++ {
+		if expanded >= maxCBSNodes {
+			return nil, errCBSLimit
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		node := heap.Pop(queue).(*cbsNode)
+		a, b, conflict, found := findConflict(node.paths, start, end)
+		if !found {
+			return turnsFromTrajectories(node.paths), nil
+		}
+
+		// Branch: either ant a or ant b has to stay out of the slot. Ants are
+		// interchangeable, so when both are under the same constraints the
+		// two branches mirror each other and only one is explored.
+		branches := []int{a, b}
+		if sameConstraints(node.constraints, a, b) {
+			branches = branches[1:]
+		}
+		for _, ant := range branches {
+			child := &cbsNode{
+				constraints: append(append([]constraint{}, node.constraints...), constraint{ant, conflict}),
+				paths:       append([][]int{}, node.paths...),
+			}
+
+			forbidden := make(map[slot]bool)
+			for _, c := range child.constraints {
+				if c.ant == ant {
+					forbidden[c.slot] = true
+				}
+			}
+
+			path := constrainedPath(routes, start, end, forbidden, g.cbsHorizon(ants, len(child.constraints)))
+			if path == nil {
+				continue
+			}
+			child.paths[ant] = path
+			for _, p := range child.paths {
+				child.cost += len(p) - 1
+			}
+			child.conflicts = countConflicts(child.paths, start, end)
+			heap.Push(queue, child)
+		}
+	}
+
+	return nil, ErrNoPath
+}
+
+func sameConstraints(constraints []constraint, a, b int) bool {
+	count := make(map[slot]int)
+	for _, c := range constraints {
+		if c.ant == a {
+			count[c.slot]++
+		} else if c.ant == b {
+			count[c.slot]--
+		}
+	}
+	for _, n := range count {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// cbsHorizon bounds how long a single ant may wander before giving up
+func (g *Graph) cbsHorizon(ants, constraints int) int {
+	return len(g.names) + ants + constraints
+}
+
+// constrainedPath finds the fastest route from start to end in the space of
+// (room, turn) states, allowing the ant to wait, while avoiding forbidden slots.
+func constrainedPath(routes [][]int, start, end int, forbidden map[slot]bool, horizon int) []int {
+	type state struct {
+		room int
+		turn int
+	}
+
+	parent := map[state]state{}
+	queue := []state{{start, 0}}
+	seen := map[state]bool{{start, 0}: true}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current.room == end {
+			path := make([]int, current.turn+1)
+			for s := current; ; s = parent[s] {
+				path[s.turn] = s.room
+				if s.turn == 0 {
+					break
+				}
+			}
+			return path
+		}
+		if current.turn >= horizon {
+			continue
+		}
+
+		// Waiting comes first, then every tunnel out of the room
+		turn := current.turn + 1
+		for i := -1; i < len(routes[current.room]); i++ {
+			room := current.room
+			if i >= 0 {
+				room = routes[current.room][i]
+			}
+			s := state{room, turn}
+			if seen[s] || forbidden[roomSlot(room, turn)] {
+				continue
+			}
+			if room != current.room && forbidden[tunnelSlot(current.room, room, turn)] {
+				continue
+			}
+			if current.room == start && room != start && forbidden[spawnSlot(start, turn)] {
+				continue
+			}
+			seen[s] = true
+			parent[s] = current
+			queue = append(queue, s)
+		}
+	}
+
+	return nil
+}
+
+// findConflict returns the first pair of ants sharing a room (other than
+// start and end) or a tunnel in the same turn.
+func findConflict(paths [][]int, start, end int) (int, int, slot, bool) {
+	var a, b int
+	var conflict slot
+	found := false
+	eachConflict(paths, start, end, func(x, y int, s slot) bool {
+		a, b, conflict, found = x, y, s, true
+		return false
+	})
+	return a, b, conflict, found
+}
+
+func countConflicts(paths [][]int, start, end int) int {
+	count := 0
+	eachConflict(paths, start, end, func(int, int, slot) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// eachConflict calls fn for every collision in turn order until fn returns false
+func eachConflict(paths [][]int, start, end int, fn func(a, b int, s slot) bool) {
+	longest := 0
+	for _, p := range paths {
+		if len(p) > longest {
+			longest = len(p)
+		}
+	}
+
+	for turn := 1; turn < longest; turn++ {
+		owner := make(map[slot]int)
+		for ant, p := range paths {
+			if turn >= len(p) {
+				continue
+			}
+
+			var slots []slot
+			if room := p[turn]; room != start && room != end {
+				slots = append(slots, roomSlot(room, turn))
+			}
+			if p[turn] != p[turn-1] {
+				slots = append(slots, tunnelSlot(p[turn-1], p[turn], turn))
+			}
+
+			for _, s := range slots {
+				if other, ok := owner[s]; ok {
+					if !fn(other, ant, s) {
+						return
+					}
+					continue
+				}
+				owner[s] = ant
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"lem2/pkg/parser"
+)
+
+// TestCertificate checks that the closed form of the certificate matches
+// the number of turns of the disjoint paths found by dfs
+func TestCertificate(t *testing.T) {
+	for _, file := range []string{"small.map", "medium.map"} {
+		c, err := parser.ParseInput("testdata/bench/"+file, parser.Strict01Edu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		solution, err := solveColony(c, []chainStage{{name: "dfs"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if err := writeCertificate(&out, solution); err != nil {
+			t.Fatal(err)
+		}
+		want := fmt.Sprintf(") = %d\n# certificate: balanced", len(solution.Turns))
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("%s: certificate does not show %d balanced turns:\n%s", file, len(solution.Turns), out.String())
+		}
+	}
+}
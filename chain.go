@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// solveTimeout bounds a whole solve (--timeout). A path search cut short
+// by it still yields a plan on the paths found so far, unlike a stage that
+// runs out of its own budget, which gives way to the next stage.
+var solveTimeout time.Duration
+
+// chainStage is one algorithm of a fallback chain together with its time
+// budget. A zero budget lets the algorithm run to completion.
+type chainStage struct {
+	name   string
+	budget time.Duration
+}
+
+// parseChain parses a chain such as "exact@5s,cbs@5s,dfs"
+func parseChain(spec string) ([]chainStage, error) {
+	var stages []chainStage
+	for _, part := range strings.Split(spec, ",") {
+		name, budget, hasBudget := strings.Cut(strings.TrimSpace(part), "@")
+		if _, ok := solvers[name]; !ok {
+			return nil, fmt.Errorf("unknown algorithm %q in chain", name)
+		}
+
+		stage := chainStage{name: name}
+		if hasBudget {
+			d, err := time.ParseDuration(budget)
+			if err != nil {
+				return nil, fmt.Errorf("invalid budget for %s: %v", name, err)
+			}
+			stage.budget = d
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+// solverStages returns the stages to run: the chain when one is given,
+// otherwise the single algorithm without a budget
+func solverStages(algo, chain string) ([]chainStage, error) {
+	if chain != "" {
+		return parseChain(chain)
+	}
+	if _, ok := solvers[algo]; !ok {
+		return nil, fmt.Errorf("unknown algorithm %s", algo)
+	}
+	return []chainStage{{name: algo}}, nil
+}
+
+// applyRules closes the rooms that the --rules file closes for this map,
+// or none without rules
+func applyRules(g *Graph, start, end, ants int) error {
+	closedSlots = nil
+	if turnRules == nil {
+		return nil
+	}
+	closed, err := evalRules(turnRules, g, start, end, ants)
+	if err != nil {
+		return err
+	}
+	closedSlots = closed
+	return nil
+}
+
+// runChain tries every stage in order and returns the first valid plan
+// produced within its budget, along with the name of the stage that won.
+func runChain(g *Graph, start, end, ants int, stages []chainStage) ([][]Move, string, error) {
+	// A single room that is both start and end needs no moves, and a start
+	// or end without tunnels cannot be reached; neither needs a solver
+	if start == end {
+		return nil, "", nil
+	}
+	if len(g.vertices[start]) == 0 || len(g.vertices[end]) == 0 {
+		return nil, "", ErrNoPath
+	}
+
+	if err := applyRules(g, start, end, ants); err != nil {
+		return nil, "", err
+	}
+	zoneLimits = newZoneLimit(g, start, end)
+
+	deadline, stop := context.Background(), context.CancelFunc(func() {})
+	if solveTimeout > 0 {
+		deadline, stop = context.WithTimeout(deadline, solveTimeout)
+	}
+	defer stop()
+
+	var lastErr error
+	for i, stage := range stages {
+		ctx, cancel := deadline, context.CancelFunc(func() {})
+		if stage.budget > 0 {
+			ctx, cancel = context.WithTimeout(deadline, stage.budget)
+		}
+
+		status.setStage(i+1, len(stages))
+		status.setPhase("solving with %s", stage.name)
+		turns, err := solvers[stage.name](ctx, g, start, end, ants)
+		cancel()
+		if err != nil && turns != nil && deadline.Err() != nil {
+			explainLog.Printf("chain: %s stopped after %v, using the paths found so far", stage.name, solveTimeout)
+			err = nil
+		}
+		if err == nil {
+			if err = validateSchedule(g, start, end, ants, turns); err != nil {
+				recordFailure(g, start, end, ants, stage.name, turns, err)
+			}
+		}
+		if err == nil && objective == objectiveSpread {
+			status.setPhase("spreading the plan from %s", stage.name)
+			turns = spreadPlan(deadline, g, start, end, ants, turns)
+		}
+		if err == nil {
+			status.foundPlan(len(turns))
+			status.setPhase("writing the plan from %s", stage.name)
+			return turns, stage.name, nil
+		}
+
+		explainLog.Printf("chain: %s failed: %v", stage.name, err)
+		lastErr = err
+	}
+	return nil, "", lastErr
+}
@@ -0,0 +1,17 @@
+package main
+
+import "flag"
+
+// parseArgs parses flags that may appear before or after the positional
+// arguments, e.g. "lem-in convert in.map --to json"
+func parseArgs(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
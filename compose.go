@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/compose"
+	"lem2/pkg/convert"
+)
+
+const composeUsage = `usage: lem-in compose union <a> <b> [-o out]
+       lem-in compose bridge <a> <b> --from <room> --to <room> [-o out]
+       lem-in compose replicate <map> --copies <n> [--suffix _] [-o out]`
+
+// runCompose implements "lem-in compose": building a colony out of others
+func runCompose(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, composeUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("compose "+args[0], flag.ExitOnError)
+	output := flags.String("o", "", "output file (default: stdout)")
+	format := flags.String("format", "map", "output format: "+strings.Join(convert.Formats, ", "))
+	from := flags.String("from", "", "bridge: room of the first colony")
+	to := flags.String("to", "", "bridge: room of the second colony")
+	copies := flags.Int("copies", 2, "replicate: number of copies")
+	suffix := flags.String("suffix", "_", "replicate: suffix added before the copy number")
+	files := parseArgs(flags, args[1:])
+
+	colonies := make([]*colony.Colony, len(files))
+	for i, file := range files {
+		c, err := readColony(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+			return 1
+		}
+		colonies[i] = c
+	}
+
+	var c *colony.Colony
+	var err error
+	switch {
+	case args[0] == "union" && len(colonies) == 2:
+		c, err = compose.Union(colonies[0], colonies[1])
+	case args[0] == "bridge" && len(colonies) == 2 && *from != "" && *to != "":
+		c, err = compose.Bridge(colonies[0], colonies[1], *from, *to)
+	case args[0] == "replicate" && len(colonies) == 1:
+		c, err = compose.Replicate(colonies[0], *copies, *suffix)
+	default:
+		fmt.Fprintln(os.Stderr, composeUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+
+	encoded, warnings, err := convert.Encode(c, *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+	if *output == "" {
+		os.Stdout.Write(encoded)
+		return 0
+	}
+	if err := os.WriteFile(*output, encoded, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	return 0
+}
+
+// readColony reads a map or, for .json files, a JSON colony
+func readColony(file string) (*colony.Colony, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	format := "map"
+	if filepath.Ext(file) == ".json" {
+		format = "json"
+	}
+	return convert.Decode(data, format)
+}
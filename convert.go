@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lem2/pkg/convert"
+)
+
+// runConvert implements "lem-in convert <in> --to <format>"
+func runConvert(args []string) int {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	to := flags.String("to", "json", "output format: "+strings.Join(convert.Formats, ", "))
+	from := flags.String("from", "", "input format (default: json for .json files, map otherwise)")
+	output := flags.String("o", "", "output file (default: stdout)")
+	files := parseArgs(flags, args)
+
+	if len(files) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in convert <in> --to "+strings.Join(convert.Formats, "|")+" [-o out]")
+		return 2
+	}
+
+	format := *from
+	if format == "" {
+		format = "map"
+		if filepath.Ext(files[0]) == ".json" {
+			format = "json"
+		}
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	c, err := convert.Decode(data, format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	encoded, warnings, err := convert.Encode(c, *to)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	if format == "map" && hasComments(c.Input) {
+		warnings = append(warnings, "comments are dropped")
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(encoded)
+		return 0
+	}
+	if err := os.WriteFile(*output, encoded, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	return 0
+}
+
+func hasComments(lines []string) bool {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		isZone := line == "##zone" || strings.HasPrefix(line, "##zone ")
+		if strings.HasPrefix(line, "#") && line != "##start" && line != "##end" && !isZone {
+			return true
+		}
+	}
+	return false
+}
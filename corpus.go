@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// corpusDir collects maps on which a solver produced an invalid plan
+// (--corpus), building up a regression suite without manual work
+var corpusDir string
+
+// failureMeta is written as meta.json next to every collected map
+type failureMeta struct {
+	Stage string    `json:"stage"`
+	Error string    `json:"error"`
+	Ants  int       `json:"ants"`
+	Turns int       `json:"turns"`
+	Time  time.Time `json:"time"`
+}
+
+// recordFailure saves the map, the invalid output and what went wrong in
+// <corpus>/<topology>-<stage>. The map is rebuilt from the graph, with every
+// room at 0,0, so failures from any caller of runChain can be collected.
+// The same failure seen twice overwrites the first copy.
+func recordFailure(g *Graph, start, end, ants int, stage string, turns [][]Move, failure error) {
+	if corpusDir == "" {
+		return
+	}
+	key := g.topology(start, end)
+	dir := filepath.Join(corpusDir, hex.EncodeToString(key[:6])+"-"+stage)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		explainLog.Printf("corpus: %v", err)
+		return
+	}
+
+	lines := []string{fmt.Sprint(ants)}
+	for room, name := range g.names {
+		switch room {
+		case start:
+			lines = append(lines, "##start")
+		case end:
+			lines = append(lines, "##end")
+		}
+		lines = append(lines, name+" 0 0")
+	}
+	for _, tunnel := range g.tunnels() {
+		lines = append(lines, g.names[tunnel[0]]+"-"+g.names[tunnel[1]])
+	}
+
+	var output strings.Builder
+	for _, moves := range turns {
+		fmt.Fprintln(&output, formatTurn(g, moves))
+	}
+	meta, _ := json.MarshalIndent(failureMeta{
+		Stage: stage,
+		Error: failure.Error(),
+		Ants:  ants,
+		Turns: len(turns),
+		Time:  time.Now().UTC(),
+	}, "", "  ")
+
+	files := map[string][]byte{
+		"input.map":  []byte(strings.Join(lines, "\n") + "\n"),
+		"output.txt": []byte(output.String()),
+		"meta.json":  append(meta, '\n'),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			explainLog.Printf("corpus: %v", err)
+			return
+		}
+	}
+	explainLog.Printf("corpus: saved the failing map in %s", dir)
+}
@@ -0,0 +1,108 @@
+package main
+
+// Rooms with more tunnels than hubDegree keep a neighbor set next to their
+// neighbor list, so tunnel lookups on hub rooms do not scan thousands of
+// neighbors.
+const hubDegree = 64
+
+// addNeighbor records b as a neighbor of a
+func (g *Graph) addNeighbor(a, b int) {
+	g.vertices[a] = append(g.vertices[a], b)
+	if set := g.hubs[a]; set != nil {
+		set[b] = true
+		return
+	}
+	if len(g.vertices[a]) > hubDegree {
+		set := make(map[int]bool, len(g.vertices[a]))
+		for _, neighbor := range g.vertices[a] {
+			set[neighbor] = true
+		}
+		g.hubs[a] = set
+	}
+}
+
+// hasEdge reports whether a tunnel connects a and b
+func (g *Graph) hasEdge(a, b int) bool {
+	if set := g.hubs[a]; set != nil {
+		return set[b]
+	}
+	if set := g.hubs[b]; set != nil {
+		return set[a]
+	}
+	if len(g.vertices[b]) < len(g.vertices[a]) {
+		a, b = b, a
+	}
+	for _, neighbor := range g.vertices[a] {
+		if neighbor == b {
+			return true
+		}
+	}
+	return false
+}
+
+// leads reports whether ants may cross the tunnel between neighbors a
+// and b from a to b, which only a directed tunnel from b to a forbids
+func (g *Graph) leads(a, b int) bool {
+	return !g.oneWay[[2]int{b, a}]
+}
+
+// deadEnds marks the rooms that cannot lie on any route from start to end
+// because, once other dead ends are removed, they have a single neighbor.
+// Ants are interchangeable, so stepping into a dead end to let another ant
+// pass never helps either; searches can skip them all. This is what keeps a
+// hub connected to thousands of leaf rooms from slowing every search down.
+func (g *Graph) deadEnds(start, end int) []bool {
+	dead := make([]bool, len(g.names))
+	degree := make([]int, len(g.names))
+	stamp := make([]int, len(g.names))
+	var queue []int
+	for room, neighbors := range g.vertices {
+		for _, neighbor := range neighbors {
+			if neighbor != room && stamp[neighbor] != room+1 {
+				stamp[neighbor] = room + 1
+				degree[room]++
+			}
+		}
+		if degree[room] <= 1 && room != start && room != end {
+			dead[room] = true
+			queue = append(queue, room)
+		}
+	}
+
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+		// A dead end has at most one live neighbor, possibly listed twice
+		for _, neighbor := range g.vertices[room] {
+			if dead[neighbor] || neighbor == room {
+				continue
+			}
+			if degree[neighbor]--; degree[neighbor] <= 1 && neighbor != start && neighbor != end {
+				dead[neighbor] = true
+				queue = append(queue, neighbor)
+			}
+			break
+		}
+	}
+	return dead
+}
+
+// routes returns the neighbor lists without dead ends, duplicate tunnels
+// and directed tunnels that lead the other way
+func (g *Graph) routes(start, end int) [][]int {
+	dead := g.deadEnds(start, end)
+	routes := make([][]int, len(g.names))
+	stamp := make([]int, len(g.names))
+	for room, neighbors := range g.vertices {
+		if dead[room] {
+			continue
+		}
+		for _, neighbor := range neighbors {
+			if !dead[neighbor] && neighbor != room && stamp[neighbor] != room+1 && g.leads(room, neighbor) {
+				stamp[neighbor] = room + 1
+				routes[room] = append(routes[room], neighbor)
+			}
+		}
+	}
+	return routes
+}
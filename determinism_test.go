@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"lem2/pkg/parser"
+)
+
+// TestDeterministicOutput solves the benchmark maps with every solver, once
+// as written and once with the rooms defined in reverse order, and expects
+// the same moves every time: nothing may depend on map iteration order or
+// on where a room is defined.
+func TestDeterministicOutput(t *testing.T) {
+	for _, file := range []string{"small.map", "medium.map"} {
+		data, err := os.ReadFile("testdata/bench/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		inputs := [][]string{lines, lines, reverseRooms(lines)}
+
+		for _, name := range sortedSolvers() {
+			var want string
+			for i, input := range inputs {
+				got := solvedMoves(t, input, name)
+				if i == 0 {
+					want = got
+				} else if got != want {
+					t.Errorf("%s/%s: run %d differs:\n%s\nwant:\n%s", file, name, i+1, got, want)
+				}
+			}
+		}
+	}
+}
+
+// solvedMoves returns the output of a solver without the echoed map, or
+// its error, which must be just as reproducible
+func solvedMoves(t *testing.T, lines []string, name string) string {
+	t.Helper()
+	c, err := parser.ParseLines(lines, parser.Strict01Edu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution, err := solveColony(c, []chainStage{{name: name}})
+	if err != nil {
+		return "ERROR: " + err.Error()
+	}
+	var out bytes.Buffer
+	if err := writeSolution(&out, c, solution); err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimPrefix(out.String(), strings.Join(lines, "\n")+"\n\n")
+}
+
+// reverseRooms reverses the order of the room definitions, keeping every
+// ##start and ##end with the room that follows it
+func reverseRooms(lines []string) []string {
+	var rooms [][]string
+	var pending []string
+	i := 1
+	for ; i < len(lines) && !strings.Contains(lines[i], "-"); i++ {
+		pending = append(pending, lines[i])
+		if !strings.HasPrefix(lines[i], "#") {
+			rooms = append(rooms, pending)
+			pending = nil
+		}
+	}
+
+	out := []string{lines[0]}
+	for j := len(rooms) - 1; j >= 0; j-- {
+		out = append(out, rooms[j]...)
+	}
+	out = append(out, pending...)
+	return append(out, lines[i:]...)
+}
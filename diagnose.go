@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"lem2/pkg/parser"
+)
+
+// diagnosticsReport is the JSON document printed by "lem-in diagnose"
+type diagnosticsReport struct {
+	Diagnostics []parser.Diagnostic `json:"diagnostics"`
+}
+
+// runDiagnose implements "lem-in diagnose": it reads a map from stdin and
+// prints every diagnostic as JSON for editor integrations. The exit status
+// is 1 when the map has errors.
+func runDiagnose(args []string) int {
+	flags := flag.NewFlagSet("diagnose", flag.ExitOnError)
+	profileName := flags.String("profile", "strict", "map format profile: "+strings.Join(parser.ProfileNames(), ", "))
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in diagnose [--profile name] < map")
+		return 2
+	}
+	profile, err := parser.LookupProfile(*profileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 2
+	}
+
+	lines, err := parser.ReadLines(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+
+	report := diagnosticsReport{Diagnostics: parser.Diagnose(lines, profile)}
+	if report.Diagnostics == nil {
+		report.Diagnostics = []parser.Diagnostic{}
+	}
+	data, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(data))
+
+	for _, d := range report.Diagnostics {
+		if d.Severity == "error" {
+			return 1
+		}
+	}
+	return 0
+}
+
+// reportVerboseError explains a parse error on stderr (--verbose-errors):
+// where it is, the line itself with a caret under the problem and the rule
+// it breaks. Errors that are not about the map, such as a missing file,
+// have nothing more to tell.
+func reportVerboseError(file string, err error) {
+	var parseErr *parser.ParseError
+	if !errors.As(err, &parseErr) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s:%s\n", file, parseErr.Diagnostic.String())
+	if parseErr.Text != "" {
+		number := strconv.Itoa(parseErr.Line)
+		fmt.Fprintf(os.Stderr, "  %s | %s\n", number, parseErr.Text)
+		fmt.Fprintf(os.Stderr, "  %s | %s^\n", strings.Repeat(" ", len(number)), caretIndent(parseErr.Text, parseErr.Column))
+	}
+	if explanation := parseErr.Reason.Explain(); explanation != "" {
+		fmt.Fprintln(os.Stderr, "  "+explanation)
+	}
+}
+
+// caretIndent returns the blanks that put a caret under the given column
+// of line, keeping its tabs so the caret lines up in a terminal
+func caretIndent(line string, column int) string {
+	var indent strings.Builder
+	for i, r := range line {
+		if i >= column-1 {
+			break
+		}
+		if r == '\t' {
+			indent.WriteRune('\t')
+		} else {
+			indent.WriteRune(' ')
+		}
+	}
+	return indent.String()
+}
+
+// reportAllErrors lists every invalid line of a map that failed to parse
+// (--all-errors). The file is read again unless its lines are given.
+func reportAllErrors(file string, lines []string, profile parser.Profile) {
+	if lines == nil {
+		var err error
+		if lines, err = parser.ReadFile(file); err != nil {
+			return
+		}
+	}
+	for _, d := range parser.Diagnose(lines, profile) {
+		if d.Severity == "error" {
+			fmt.Fprintf(os.Stderr, "%s:%s\n", file, d.String())
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/convert"
+	"lem2/pkg/parser"
+	"lem2/pkg/pathfinder"
+	"lem2/pkg/verifier"
+)
+
+// TestEdgeMaps runs every solver on the degenerate maps in testdata/edge
+func TestEdgeMaps(t *testing.T) {
+	tests := []struct {
+		file  string
+		err   error
+		turns int
+	}{
+		{"no-tunnels.map", ErrNoPath, 0},
+		{"start-is-end.map", nil, 0},
+	}
+
+	for _, test := range tests {
+		c, err := parser.ParseInput("testdata/edge/"+test.file, parser.Strict01Edu)
+		if err != nil {
+			t.Fatalf("%s: %v", test.file, err)
+		}
+		for _, name := range sortedSolvers() {
+			solution, err := solveColony(c, []chainStage{{name: name}})
+			if !errors.Is(err, test.err) {
+				t.Errorf("%s/%s: got error %v, want %v", test.file, name, err, test.err)
+				continue
+			}
+			if err == nil && len(solution.Turns) != test.turns {
+				t.Errorf("%s/%s: got %d turns, want %d", test.file, name, len(solution.Turns), test.turns)
+			}
+		}
+	}
+}
+
+// orderTests are the maps in testdata/order, where start and end are not
+// defined in the usual order
+var orderTests = []struct {
+	file       string
+	start, end string
+	strict     bool  // whether the strict profile accepts the map
+	err        error // expected from every solver
+}{
+	{"end-first.map", "start", "end", true, nil},
+	{"start-amid-tunnels.map", "start", "end", false, nil},
+	{"isolated-start.map", "start", "end", true, ErrNoPath},
+}
+
+func TestOrderParse(t *testing.T) {
+	for _, test := range orderTests {
+		c, err := parser.ParseInput("testdata/order/"+test.file, parser.Lenient)
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+		if c.Start != test.start || c.End != test.end {
+			t.Errorf("%s: got start %s and end %s, want %s and %s", test.file, c.Start, c.End, test.start, test.end)
+		}
+
+		_, err = parser.ParseInput("testdata/order/"+test.file, parser.Strict01Edu)
+		if (err == nil) != test.strict {
+			t.Errorf("%s: strict profile returned %v", test.file, err)
+		}
+	}
+}
+
+// TestOrderSolve runs every solver, including the validation of its plan
+func TestOrderSolve(t *testing.T) {
+	for _, test := range orderTests {
+		c, err := parser.ParseInput("testdata/order/"+test.file, parser.Lenient)
+		if err != nil {
+			t.Fatalf("%s: %v", test.file, err)
+		}
+		for _, name := range sortedSolvers() {
+			solution, err := solveColony(c, []chainStage{{name: name}})
+			if !errors.Is(err, test.err) {
+				t.Errorf("%s/%s: got error %v, want %v", test.file, name, err, test.err)
+				continue
+			}
+			if err == nil && len(solution.Turns) == 0 {
+				t.Errorf("%s/%s: no moves", test.file, name)
+			}
+		}
+	}
+}
+
+// TestOrderOutput checks that the map is echoed as written, not reordered
+func TestOrderOutput(t *testing.T) {
+	for _, test := range orderTests {
+		if test.err != nil {
+			continue
+		}
+		c, err := parser.ParseInput("testdata/order/"+test.file, parser.Lenient)
+		if err != nil {
+			t.Fatalf("%s: %v", test.file, err)
+		}
+		solution, err := solveColony(c, []chainStage{{name: "auto"}})
+		if err != nil {
+			t.Fatalf("%s: %v", test.file, err)
+		}
+		var out bytes.Buffer
+		if err := writeSolution(&out, c, solution); err != nil {
+			t.Fatal(err)
+		}
+		echo := strings.Join(c.Input, "\n") + "\n\n"
+		if !strings.HasPrefix(out.String(), echo) {
+			t.Errorf("%s: output does not start with the map:\n%s", test.file, out.String())
+		}
+	}
+}
+
+// verifySolution writes the solution in the text output and checks it
+// with the verifier, reporting a failure under label
+func verifySolution(t *testing.T, label string, c *colony.Colony, solution *Solution) {
+	t.Helper()
+	var out bytes.Buffer
+	if err := writeSolution(&out, c, solution); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if err := verifier.Verify(c, verifier.StripEcho(c, lines)); err != nil {
+		t.Errorf("%s: %v\n%s", label, err, out.String())
+	}
+}
+
+// TestStartIsEndRoundTrip checks that fmt and convert keep both ##start
+// and ##end on a room that is both, so their output parses back to the
+// same colony
+func TestStartIsEndRoundTrip(t *testing.T) {
+	const file = "testdata/edge/start-is-end.map"
+	c, err := parser.ParseInput(file, parser.Strict01Edu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatted, err := parser.Format(c.Input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted, err := convert.ToMap(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, lines := range map[string][]string{"fmt": formatted, "convert": converted} {
+		again, err := parser.ParseLines(lines, parser.Strict01Edu)
+		if err != nil {
+			t.Errorf("%s: %v\n%s", name, err, strings.Join(lines, "\n"))
+			continue
+		}
+		if again.Start != c.Start || again.End != c.End || again.Ants != c.Ants {
+			t.Errorf("%s: got start %s, end %s and %d ants, want %s, %s and %d", name, again.Start, again.End, again.Ants, c.Start, c.End, c.Ants)
+		}
+	}
+}
+
+// TestMultipleTerminals solves a map with two start and two end rooms,
+// which the lenient profile reads as two entrances and two exits, and
+// checks the output with the verifier
+func TestMultipleTerminals(t *testing.T) {
+	c, err := parser.ParseInput("testdata/edge/multiple-terminals.map", parser.Lenient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsStart("s2") || !c.IsEnd("e2") {
+		t.Fatalf("got start rooms %v and end rooms %v", c.StartRooms(), c.EndRooms())
+	}
+	for _, name := range sortedSolvers() {
+		solution, err := solveColony(c, []chainStage{{name: name}})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(solution.Turns) != 4 {
+			t.Errorf("%s: got %d turns, want 4", name, len(solution.Turns))
+		}
+		verifySolution(t, name, c, solution)
+	}
+}
+
+// TestDirectedTunnels solves a map where a directed tunnel closes the
+// second of two paths, so every solver must keep to the first one
+func TestDirectedTunnels(t *testing.T) {
+	c, err := parser.ParseInput("testdata/edge/directed.map", parser.Lenient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range sortedSolvers() {
+		solution, err := solveColony(c, []chainStage{{name: name}})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(solution.Turns) != 5 {
+			t.Errorf("%s: got %d turns, want 5", name, len(solution.Turns))
+		}
+		verifySolution(t, name, c, solution)
+	}
+}
+
+// TestZoneCapacity solves a map with four parallel paths through a zone
+// that holds two ants, so only two ants can cross it per turn: 5 turns
+// instead of 3. The solvers without zone support must refuse the map.
+func TestZoneCapacity(t *testing.T) {
+	c, err := parser.ParseInput("testdata/edge/zone-capacity.map", parser.Lenient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range sortedSolvers() {
+		solution, err := solveColony(c, []chainStage{{name: name}})
+		if name == "exact" || name == "cbs" {
+			if !errors.Is(err, errZoneCapacity) {
+				t.Errorf("%s: got error %v, want %v", name, err, errZoneCapacity)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(solution.Turns) != 5 {
+			t.Errorf("%s: got %d turns, want 5", name, len(solution.Turns))
+		}
+		if zones := solution.stats().Zones; len(zones) != 1 || zones[0].Peak != 2 {
+			t.Errorf("%s: got zone stats %+v, want a peak of 2", name, zones)
+		}
+		verifySolution(t, name, c, solution)
+	}
+}
+
+// TestSpread plans a map with paths of 1, 2 and 3 rooms for the spread
+// objective. The fastest plan sends 3 of the 6 ants through the shortest
+// path in 4 turns; no plan that fast does better, but with a budget of 5
+// turns every room sees at most 2 ants.
+func TestSpread(t *testing.T) {
+	c, err := parser.ParseInput("testdata/edge/spread.map", parser.Strict01Edu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { objective, turnBudget = objectiveTurns, 0 }()
+	objective = objectiveSpread
+	for _, test := range []struct{ budget, turns, peak int }{{0, 4, 3}, {5, 5, 2}} {
+		turnBudget = test.budget
+		solution, err := solveColony(c, []chainStage{{name: "dfs"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		peak := peakAntTurns(solution.Graph, solution.Start, solution.End, solution.Turns)
+		if len(solution.Turns) != test.turns || peak != test.peak {
+			t.Errorf("budget %d: got %d turns and a peak of %d, want %d and %d", test.budget, len(solution.Turns), peak, test.turns, test.peak)
+		}
+		verifySolution(t, fmt.Sprint("budget ", test.budget), c, solution)
+	}
+}
+
+// TestTimeout checks that when the context ends during the path search on
+// a grid, the solver still returns a valid plan on the paths found so far.
+func TestTimeout(t *testing.T) {
+	const size = 8
+	c := colony.New()
+	c.Ants, c.Start, c.End = 20, "0-0", fmt.Sprintf("%d-%d", size-1, size-1)
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			name := fmt.Sprintf("%d-%d", x, y)
+			c.Rooms[name] = &colony.Room{Name: name, X: x, Y: y}
+			if x > 0 {
+				c.AddTunnel(colony.Tunnel{From: fmt.Sprintf("%d-%d", x-1, y), To: name})
+			}
+			if y > 0 {
+				c.AddTunnel(colony.Tunnel{From: fmt.Sprintf("%d-%d", x, y-1), To: name})
+			}
+		}
+	}
+	g := graphFromColony(c)
+	start, _ := g.ID(c.Start)
+	end, _ := g.ID(c.End)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	turns, err := solveDFS(ctx, g, start, end, c.Ants)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if turns == nil {
+		t.Fatal("got no plan from the paths found before the cancellation")
+	}
+	if err := validateSchedule(g, start, end, c.Ants, turns); err != nil {
+		t.Error(err)
+	}
+
+	paths, err := pathfinder.FindPathsCtx(ctx, graphStrategy((*Graph).disjointPaths), c)
+	if !errors.Is(err, context.Canceled) || len(paths) != 0 {
+		t.Errorf("disjoint-flow: got %d paths and error %v, want none and %v", len(paths), err, context.Canceled)
+	}
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"lem2/pkg/parser"
+	"lem2/pkg/pathfinder"
+)
+
+// Sentinel errors of the solvers, meant to be tested with errors.Is. They
+// live in importable packages so library users can test for them too: a
+// missing path is the parser's ErrNoPath, whether the parser or a solver
+// finds out, and solvers hitting their size limits return a
+// pathfinder.LimitError.
+var (
+	ErrNoPath        = parser.ErrNoPath
+	ErrLimitExceeded = pathfinder.ErrLimitExceeded
+)
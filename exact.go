@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+
+	"lem2/pkg/pathfinder"
+)
+
+// maxExactNodes caps the size of the time-expanded network so the exact
+// scheduler is only used on small and medium maps.
+const maxExactNodes = 500000
+
+var errExactTooLarge error = pathfinder.LimitError("map too large for the exact scheduler")
+
+// tunnels returns every tunnel once, ignoring duplicate AddEdge calls
+func (g *Graph) tunnels() [][2]int {
+	seen := make(map[[2]int]bool)
+	var result [][2]int
+	for a, neighbors := range g.vertices {
+		for _, b := range neighbors {
+			key := [2]int{a, b}
+			if b < a {
+				key = [2]int{b, a}
+			}
+			if a == b || seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// distance returns the number of tunnels on the shortest path from start to end, or -1
+func (g *Graph) distance(start, end int) int {
+	dist := make([]int, len(g.names))
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[start] = 0
+	queue := []int{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == end {
+			return dist[current]
+		}
+		for _, neighbor := range g.vertices[current] {
+			if dist[neighbor] < 0 && g.leads(current, neighbor) {
+				dist[neighbor] = dist[current] + 1
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+	return -1
+}
+
+// ExactSchedule computes a schedule with the minimal number of turns by
+// solving max-flow on the time-expanded graph, where every node is a
+// (room, turn) pair. Unlike path based schedulers it may route ants over
+// overlapping paths and let them wait inside rooms.
+func (g *Graph) ExactSchedule(ctx context.Context, start, end, ants int) ([][]Move, error) {
+	if ants <= 0 || start == end {
+		return nil, nil
+	}
+	if closedSlots != nil {
+		return nil, errRules
+	}
+	if zoneLimits != nil {
+		return nil, errZoneCapacity
+	}
+
+	shortest := g.distance(start, end)
+	if shortest < 0 {
+		return nil, ErrNoPath
+	}
+
+	// Dead ends never help, so they are left out of the network
+	dead := g.deadEnds(start, end)
+	var tunnels [][2]int
+	for _, tunnel := range g.tunnels() {
+		if !dead[tunnel[0]] && !dead[tunnel[1]] {
+			tunnels = append(tunnels, tunnel)
+		}
+	}
+	rooms := 0
+	for _, d := range dead {
+		if !d {
+			rooms++
+		}
+	}
+
+	// Sending every ant down the shortest path one after another always
+	// works, so the optimum lies between these two bounds.
+	upper := shortest + ants - 1
+	if (2*rooms+2*len(tunnels))*(upper+1) > maxExactNodes {
+		return nil, errExactTooLarge
+	}
+
+	// Each extra turn only appends a layer to the network, so the flow
+	// found so far stays valid and only the missing units are augmented.
+	te := newTimeExpanded(dead, tunnels, g.oneWay, start, end, ants)
+	flow := 0
+	for turns := 1; turns <= upper; turns++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		te.addTurn()
+		if turns < shortest {
+			continue
+		}
+		status.setPhase("exact: trying %d turns", turns)
+		flow += te.net.maxFlow(te.source, te.sink, ants-flow)
+		if flow == ants {
+			return te.schedule(), nil
+		}
+	}
+
+	return nil, ErrNoPath
+}
+
+// timeExpanded is the (room, turn) flow network, grown one turn at a time.
+// Rooms are split into in/out nodes to enforce one ant per room, and every
+// tunnel gets its own split node per turn so it carries one ant at a time.
+type timeExpanded struct {
+	net          *flowNetwork
+	dead         []bool // rooms left out of the network
+	tunnels      [][2]int
+	oneWay       map[[2]int]bool // directed tunnels, from first
+	start, end   int
+	ants         int
+	layers       [][]int // in-node of every room per turn, -1 for dead ends
+	roomOf       []int   // room of every in-node, -1 for other nodes
+	source, sink int
+}
+
+func newTimeExpanded(dead []bool, tunnels [][2]int, oneWay map[[2]int]bool, start, end, ants int) *timeExpanded {
+	te := &timeExpanded{
+		net:     newFlowNetwork(0),
+		dead:    dead,
+		tunnels: tunnels,
+		oneWay:  oneWay,
+		start:   start,
+		end:     end,
+		ants:    ants,
+	}
+
+	te.sink = te.addNode(-1)
+	te.addLayer()
+	te.source = te.layers[0][te.start]
+	return te
+}
+
+func (te *timeExpanded) addNode(room int) int {
+	te.roomOf = append(te.roomOf, room)
+	return te.net.addNode()
+}
+
+// addLayer adds the in/out nodes of every room for the next turn
+func (te *timeExpanded) addLayer() {
+	layer := make([]int, len(te.dead))
+	for room := range layer {
+		if te.dead[room] {
+			layer[room] = -1
+			continue
+		}
+		// The out-node always directly follows the in-node
+		layer[room] = te.addNode(room)
+		out := te.addNode(-1)
+
+		capacity := 1
+		switch {
+		case room == te.end && exitCapacity > 0:
+			capacity = min(exitCapacity, te.ants)
+		case room == te.start || room == te.end:
+			capacity = te.ants
+		}
+		te.net.addEdge(layer[room], out, capacity)
+		if room == te.end {
+			te.net.addEdge(out, te.sink, te.ants)
+		}
+	}
+	te.layers = append(te.layers, layer)
+}
+
+// addTurn extends the network by one turn, connecting the previous layer
+// to a new one through waiting edges and tunnels.
+func (te *timeExpanded) addTurn() {
+	prev := te.layers[len(te.layers)-1]
+	te.addLayer()
+	next := te.layers[len(te.layers)-1]
+
+	for room := range next {
+		if room != te.end && !te.dead[room] {
+			te.net.addEdge(prev[room]+1, next[room], te.ants)
+		}
+	}
+
+	// With a spawn rate, ants leave start through one more node per turn
+	// whose capacity is the rate
+	departures := prev[te.start] + 1
+	if spawnRate > 0 {
+		departures = te.addNode(-1)
+		te.net.addEdge(prev[te.start]+1, departures, min(spawnRate, te.ants))
+	}
+
+	for _, tunnel := range te.tunnels {
+		node := te.addNode(-1)
+		out := te.addNode(-1)
+		te.net.addEdge(node, out, 1)
+		for _, pair := range [][2]int{tunnel, {tunnel[1], tunnel[0]}} {
+			from, to := pair[0], pair[1]
+			if from == te.end || to == te.start || te.oneWay[[2]int{to, from}] {
+				continue
+			}
+			if from == te.start {
+				te.net.addEdge(departures, node, 1)
+			} else {
+				te.net.addEdge(prev[from]+1, node, 1)
+			}
+			te.net.addEdge(out, next[to], 1)
+		}
+	}
+}
+
+// schedule decomposes the flow into one trajectory per ant and converts
+// them into per-turn moves. Ants are numbered in order of departure.
+func (te *timeExpanded) schedule() [][]Move {
+	var trajectories [][]int
+
+	for {
+		var trajectory []int
+		node := te.source
+		for node != te.sink {
+			// Room in-nodes are visited once per turn
+			if room := te.roomOf[node]; room >= 0 {
+				trajectory = append(trajectory, room)
+			}
+			next := -1
+			for _, e := range te.net.head[node] {
+				if e%2 == 0 && te.net.flow(e) > 0 {
+					te.net.cap[e^1]--
+					next = te.net.to[e]
+					break
+				}
+			}
+			if next < 0 {
+				break
+			}
+			node = next
+		}
+		if node != te.sink {
+			break
+		}
+		trajectories = append(trajectories, trajectory)
+	}
+
+	return turnsFromTrajectories(trajectories)
+}
@@ -0,0 +1,61 @@
+package main
+
+import "errors"
+
+// exitCapacity limits how many ants may enter the end room in one turn
+// (--exit-capacity), modelling a narrow exit. Zero means no limit.
+var exitCapacity int
+
+var errExitCapacity = errors.New("the CBS solver does not support an exit capacity")
+
+// exitCounter counts the ants entering the end room per turn and reserves
+// the end room in turns that reached the exit capacity
+type exitCounter struct {
+	end      int
+	arrivals map[int]int
+}
+
+func newExitCounter(end int) *exitCounter {
+	return &exitCounter{end: end, arrivals: make(map[int]int)}
+}
+
+// arrive records an ant entering the end room on turn
+func (c *exitCounter) arrive(table reservationTable, turn int) {
+	c.arrivals[turn]++
+	if exitCapacity > 0 && c.arrivals[turn] >= exitCapacity {
+		table[roomSlot(c.end, turn)] = true
+	}
+}
+
+// ExitStats shows whether the exit capacity limited the plan. The plan
+// cannot be shorter than ExitBound turns: the shortest path plus one turn
+// for every further group of Capacity ants.
+type ExitStats struct {
+	Capacity  int  `json:"capacity"`
+	FullTurns int  `json:"full_turns"` // turns in which Capacity ants entered the end room
+	ExitBound int  `json:"exit_bound"`
+	Binding   bool `json:"binding"` // the plan takes exactly ExitBound turns
+}
+
+func (s *Solution) exitStats(ants int) *ExitStats {
+	if exitCapacity <= 0 {
+		return nil
+	}
+	st := &ExitStats{Capacity: exitCapacity}
+	for _, moves := range s.Turns {
+		entered := 0
+		for _, move := range moves {
+			if move.Room == s.End {
+				entered++
+			}
+		}
+		if entered >= exitCapacity {
+			st.FullTurns++
+		}
+	}
+	if shortest := s.Graph.distance(s.Start, s.End); shortest > 0 {
+		st.ExitBound = shortest + (ants+exitCapacity-1)/exitCapacity - 1
+		st.Binding = len(s.Turns) == st.ExitBound
+	}
+	return st
+}
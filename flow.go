@@ -0,0 +1,82 @@
+package main
+
+// flowNetwork is a residual graph used by the max-flow based algorithms.
+// Edges are stored in pairs so that edge e and its reverse are e and e^1.
+type flowNetwork struct {
+	head [][]int // outgoing edge ids per node
+	to   []int
+	cap  []int
+}
+
+func newFlowNetwork(nodes int) *flowNetwork {
+	return &flowNetwork{head: make([][]int, nodes)}
+}
+
+// addNode appends a node and returns its id. Nodes and edges may be added
+// after flow has been pushed; the residual graph stays valid, so a later
+// maxFlow call only augments what the new edges make possible.
+func (f *flowNetwork) addNode() int {
+	f.head = append(f.head, nil)
+	return len(f.head) - 1
+}
+
+// addEdge adds a directed edge with the given capacity and returns its id
+func (f *flowNetwork) addEdge(u, v, capacity int) int {
+	id := len(f.to)
+	f.to = append(f.to, v, u)
+	f.cap = append(f.cap, capacity, 0)
+	f.head[u] = append(f.head[u], id)
+	f.head[v] = append(f.head[v], id+1)
+	return id
+}
+
+// flow returns the amount of flow currently pushed through edge e
+func (f *flowNetwork) flow(e int) int {
+	return f.cap[e^1]
+}
+
+// maxFlow pushes flow from s to t along shortest augmenting paths
+// (Edmonds-Karp) until no path is left or limit units have been pushed.
+func (f *flowNetwork) maxFlow(s, t, limit int) int {
+	total := 0
+	parent := make([]int, len(f.head))
+
+	for total < limit {
+		for i := range parent {
+			parent[i] = -1
+		}
+		parent[s] = -2
+
+		queue := []int{s}
+		for len(queue) > 0 && parent[t] == -1 {
+			u := queue[0]
+			queue = queue[1:]
+			for _, e := range f.head[u] {
+				v := f.to[e]
+				if f.cap[e] > 0 && parent[v] == -1 {
+					parent[v] = e
+					queue = append(queue, v)
+				}
+			}
+		}
+
+		if parent[t] == -1 {
+			break
+		}
+
+		// Find the bottleneck along the path and push it
+		push := limit - total
+		for v := t; v != s; v = f.to[parent[v]^1] {
+			if c := f.cap[parent[v]]; c < push {
+				push = c
+			}
+		}
+		for v := t; v != s; v = f.to[parent[v]^1] {
+			f.cap[parent[v]] -= push
+			f.cap[parent[v]^1] += push
+		}
+		total += push
+	}
+
+	return total
+}
@@ -0,0 +1,130 @@
+package main
+
+import "context"
+
+// disjointPaths finds vertex-disjoint paths from start to end with
+// disjointPathSets. For few ants more paths can be slower than fewer short
+// ones, so the set that moves the ants in the fewest turns is kept. When
+// ctx ends the search, the best set so far is returned with the error.
+func (g *Graph) disjointPaths(ctx context.Context, start, end, ants int) ([][]int, error) {
+	var best [][]int
+	bestTurns := 0
+	var err error
+	g.disjointPathSets(start, end, ants, func(paths [][]int) bool {
+		if err = ctx.Err(); err != nil {
+			return false
+		}
+		lengths := make([]int, len(paths))
+		for i, path := range paths {
+			lengths[i] = len(path) - 1
+		}
+		turns := disjointTurns(lengths, ants)
+		explainLog.Printf("flow: %d disjoint paths take %d turns", len(paths), turns)
+		if best == nil || turns < bestTurns {
+			best, bestTurns = paths, turns
+		}
+		return true
+	})
+	return best, err
+}
+
+// disjointPathSets runs Edmonds-Karp from start to end on the graph with
+// every room split into an in-node and an out-node joined by a unit edge.
+// Flow is augmented one path at a time, up to limit paths, and after every
+// augmentation the flow is decomposed into paths and passed to visit,
+// which stops the search by returning false.
+func (g *Graph) disjointPathSets(start, end, limit int, visit func(paths [][]int) bool) {
+	routes := g.routes(start, end)
+	n := len(g.names)
+	net := newFlowNetwork(2 * n)
+
+	// Room r is split into 2r (in) and 2r+1 (out)
+	for room := 0; room < n; room++ {
+		capacity := 1
+		if room == start || room == end {
+			capacity = limit
+		}
+		net.addEdge(2*room, 2*room+1, capacity)
+	}
+	// Tunnel edges are added in both directions; opposite[e] is the edge
+	// for the other direction, so flow crossing a tunnel both ways cancels.
+	// A directed tunnel only gets its own direction and is listed in the
+	// routes of its first room only.
+	opposite := make(map[int]int)
+	for a, neighbors := range routes {
+		for _, b := range neighbors {
+			switch {
+			case !g.leads(b, a):
+				net.addEdge(2*a+1, 2*b, 1)
+			case a < b:
+				ab := net.addEdge(2*a+1, 2*b, 1)
+				ba := net.addEdge(2*b+1, 2*a, 1)
+				opposite[ab], opposite[ba] = ba, ab
+			}
+		}
+	}
+
+	for flow := 0; flow < limit; flow++ {
+		if net.maxFlow(2*start, 2*end+1, 1) == 0 {
+			return
+		}
+		if !visit(decomposeFlow(net, opposite, start, end)) {
+			return
+		}
+	}
+}
+
+// decomposeFlow follows the unit flows out of start to list the paths they
+// form. Tunnels crossed in both directions carry no flow.
+func decomposeFlow(net *flowNetwork, opposite map[int]int, start, end int) [][]int {
+	used := make(map[int]bool)
+	carries := func(e int) bool {
+		other, isTunnel := opposite[e]
+		return e%2 == 0 && net.flow(e) > 0 && !used[e] && !(isTunnel && net.flow(other) > 0)
+	}
+
+	var paths [][]int
+	for {
+		path := []int{start}
+		for room := start; room != end; {
+			next := -1
+			for _, e := range net.head[2*room+1] {
+				if carries(e) {
+					used[e] = true
+					next = net.to[e] / 2
+					break
+				}
+			}
+			if next < 0 {
+				return paths
+			}
+			path = append(path, next)
+			room = next
+		}
+		paths = append(paths, path)
+	}
+}
+
+// disjointTurns returns the number of turns needed to move ants along
+// vertex-disjoint paths with the given numbers of tunnels. Every ant takes
+// the path on which it arrives first, which is optimal for disjoint paths.
+// With a spawn rate, ants cannot leave start faster than the rate allows.
+func disjointTurns(lengths []int, ants int) int {
+	queued := make([]int, len(lengths))
+	turns := 0
+	for ant := 0; ant < ants; ant++ {
+		best := 0
+		for i := range lengths {
+			if lengths[i]+queued[i] < lengths[best]+queued[best] {
+				best = i
+			}
+		}
+		queued[best]++
+		arrival := lengths[best] + queued[best] - 1
+		if spawnRate > 0 {
+			arrival = max(arrival, lengths[best]+ant/spawnRate)
+		}
+		turns = max(turns, arrival)
+	}
+	return turns
+}
@@ -0,0 +1,63 @@
+package main
+
+import "sort"
+
+// tunnelFlow is the number of ants that crossed a tunnel in one direction
+type tunnelFlow struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Ants int    `json:"ants"`
+}
+
+// flowDecomposition replays the solution and reports, per tunnel and
+// direction, how many ants went through it. For the exact scheduler this is
+// the flow decomposition of the max-flow; tunnels missing from the result
+// carry no flow at all.
+func (s *Solution) flowDecomposition() []tunnelFlow {
+	position := make(map[int]int)
+	count := make(map[[2]int]int)
+	for _, moves := range s.Turns {
+		for _, move := range moves {
+			from, ok := position[move.Ant]
+			if !ok {
+				from = s.Start
+			}
+			count[[2]int{from, move.Room}]++
+			position[move.Ant] = move.Room
+		}
+	}
+
+	flows := make([]tunnelFlow, 0, len(count))
+	for tunnel, ants := range count {
+		flows = append(flows, tunnelFlow{From: s.Graph.Name(tunnel[0]), To: s.Graph.Name(tunnel[1]), Ants: ants})
+	}
+	sort.Slice(flows, func(i, j int) bool {
+		if flows[i].Ants != flows[j].Ants {
+			return flows[i].Ants > flows[j].Ants
+		}
+		if flows[i].From != flows[j].From {
+			return flows[i].From < flows[j].From
+		}
+		return flows[i].To < flows[j].To
+	})
+	return flows
+}
+
+// explainFlow logs the flow decomposition and the tunnels left unused
+func (s *Solution) explainFlow() {
+	if !explaining() {
+		return
+	}
+	used := make(map[[2]string]bool)
+	for _, flow := range s.flowDecomposition() {
+		explainLog.Printf("flow: %s -> %s carries %d ants", flow.From, flow.To, flow.Ants)
+		used[[2]string{flow.From, flow.To}] = true
+		used[[2]string{flow.To, flow.From}] = true
+	}
+	for _, tunnel := range s.Graph.tunnels() {
+		a, b := s.Graph.Name(tunnel[0]), s.Graph.Name(tunnel[1])
+		if !used[[2]string{a, b}] {
+			explainLog.Printf("flow: %s - %s unused", a, b)
+		}
+	}
+}
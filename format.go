@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"lem2/pkg/parser"
+)
+
+// runFmt implements "lem-in fmt": it rewrites maps in canonical form, or
+// with --check only lists the maps that are not canonical.
+func runFmt(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	check := flags.Bool("check", false, "report maps that are not formatted instead of rewriting them")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in fmt [--check] <map>...")
+		return 2
+	}
+
+	status := 0
+	for _, file := range flags.Args() {
+		lines, err := parser.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			status = 1
+			continue
+		}
+		formatted, err := parser.Format(lines)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			status = 1
+			continue
+		}
+
+		if strings.Join(lines, "\n") == strings.Join(formatted, "\n") {
+			continue
+		}
+		if *check {
+			fmt.Println(file)
+			status = 1
+			continue
+		}
+		if err := os.WriteFile(file, []byte(strings.Join(formatted, "\n")+"\n"), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+			status = 1
+		}
+	}
+	return status
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/render"
+	"lem2/pkg/simulator"
+)
+
+// writeFrames writes the solution for renderers outside lem-in (--frames)
+// as JSON Lines: the render.Scene first, then one render.Frame per turn,
+// starting with turn 0
+func writeFrames(w io.Writer, c *colony.Colony, s *Solution) error {
+	turns := make([][]simulator.Move, len(s.Turns))
+	names := s.roomNames()
+	for i, moves := range s.Turns {
+		for j, move := range moves {
+			turns[i] = append(turns[i], simulator.Move{Ant: move.Ant, Room: names[i][j]})
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+	frames := render.Frames(c, turns)
+	if err := encoder.Encode(frames.Scene()); err != nil {
+		return err
+	}
+	for frame, ok := frames.Next(); ok; frame, ok = frames.Next() {
+		if err := encoder.Encode(frame); err != nil {
+			return err
+		}
+	}
+	if err := frames.Err(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"lem2/pkg/generator"
+)
+
+// runGenerate implements "lem-in generate": it writes a random colony
+func runGenerate(args []string) int {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	var o generator.Options
+	flags.StringVar(&o.Model, "model", "ba", "graph model: "+strings.Join(generator.Models(), ", "))
+	flags.IntVar(&o.Rooms, "rooms", 100, "number of rooms")
+	flags.IntVar(&o.Ants, "ants", 10, "number of ants")
+	flags.Int64Var(&o.Seed, "seed", 1, "random seed")
+	flags.IntVar(&o.M, "m", 2, "ba: tunnels added with every new room; hub: routes into and out of the hub")
+	flags.IntVar(&o.K, "k", 4, "ws: neighbors of every room in the initial ring (even)")
+	flags.Float64Var(&o.Beta, "beta", 0.1, "ws: probability of rewiring each tunnel")
+	flags.IntVar(&o.DisjointPaths, "disjoint-paths", 0, "build exactly k vertex-disjoint paths from start to end")
+	lengths := flags.String("path-lengths", "3", "disjoint: comma-separated tunnels per path, the last one repeats")
+	flags.IntVar(&o.Distractors, "distractors", 0, "disjoint: tunnels that add no new path")
+	flags.IntVar(&o.Tunnels, "tunnels", 0, "build a connected random graph with exactly this many tunnels")
+	output := flags.String("o", "", "output file (default: stdout)")
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in generate [--model ba|ws|hub] [--disjoint-paths k [--path-lengths 3,5] [--distractors n]] [--tunnels n] [--rooms n] [--ants n] [--seed s] [-o out]")
+		return 2
+	}
+
+	for _, field := range strings.Split(*lengths, ",") {
+		length, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR: invalid path length", field)
+			return 2
+		}
+		o.PathLengths = append(o.PathLengths, length)
+	}
+
+	lines, err := generator.Generate(o)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	data := []byte(strings.Join(lines, "\n") + "\n")
+	if *output == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// outputHeader (--header) adds comment lines describing how the output was
+// made: the build of the solver, the algorithm that produced the plan, and
+// the number of turns and paths. They follow the echoed map rather than
+// preceding it, since the number of ants must come first; verifiers skip
+// them like any other comment, so stored outputs stay valid while
+// remaining interpretable without a manifest.
+var outputHeader bool
+
+func writeHeader(w io.Writer, s *Solution) error {
+	build := currentBuild()
+	solver := build.Module + " " + build.Version
+	if build.Revision != "" {
+		solver += " " + build.Revision
+		if build.Modified {
+			solver += "+modified"
+		}
+	}
+	stage := s.Stage
+	if stage == "" {
+		stage = "none (no moves needed)"
+	}
+	_, err := fmt.Fprintf(w, "# solver: %s (%s)\n# algorithm: %s\n# turns: %d\n# paths: %d\n",
+		solver, build.GoVersion, stage, len(s.Turns), len(s.paths()))
+	return err
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// heuristicProfile holds the tunable numbers behind the algorithm choices
+// that are not exact: when auto switches from exact to dfs to flow, and
+// how many paths bounded looks at. The best values depend on the kind of
+// map, so they can be loaded from a JSON file (--heuristics) and
+// overridden one by one with flags.
+type heuristicProfile struct {
+	AutoExactNodes int `json:"auto_exact_nodes"` // auto uses exact up to this time-expanded size
+	AutoPaths      int `json:"auto_paths"`       // auto uses dfs below this many paths, flow otherwise
+	BoundedPaths   int `json:"bounded_paths"`    // paths found before bounded stops searching
+}
+
+var defaultHeuristics = heuristicProfile{
+	AutoExactNodes: maxExactNodes / 10,
+	AutoPaths:      1000,
+	BoundedPaths:   50,
+}
+
+// heuristics is the profile in use
+var heuristics = defaultHeuristics
+
+// loadHeuristics reads a profile file. Numbers it leaves out keep their
+// defaults; unknown names are rejected so a typo cannot go unnoticed.
+func loadHeuristics(file string) (heuristicProfile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return heuristicProfile{}, err
+	}
+	profile := defaultHeuristics
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&profile); err != nil {
+		return heuristicProfile{}, fmt.Errorf("%s: %v", file, err)
+	}
+	if err := profile.check(); err != nil {
+		return heuristicProfile{}, fmt.Errorf("%s: %v", file, err)
+	}
+	return profile, nil
+}
+
+func (h heuristicProfile) check() error {
+	if h.AutoExactNodes < 0 || h.AutoPaths <= 0 || h.BoundedPaths <= 0 {
+		return fmt.Errorf("invalid heuristics %+v: auto_paths and bounded_paths must be positive, auto_exact_nodes must not be negative", h)
+	}
+	return nil
+}
+
+// save writes the profile in the format read by loadHeuristics
+func (h heuristicProfile) save(file string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
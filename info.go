@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+)
+
+// runInfo implements "lem-in info <map>": a summary of the colony,
+// including the minimum cut between start and end
+func runInfo(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in info <map>")
+		return 2
+	}
+
+	c, err := parser.ParseInput(args[0], parser.Strict01Edu)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	writeInfo(os.Stdout, c)
+	return 0
+}
+
+// writeInfo writes the summary of c printed by "lem-in info"
+func writeInfo(w io.Writer, c *colony.Colony) {
+	graph := graphFromColony(c)
+	start, _ := graph.ID(c.Start)
+	end, _ := graph.ID(c.End)
+
+	fmt.Fprintln(w, "ants:", c.Ants)
+	fmt.Fprintln(w, "rooms:", len(c.Rooms))
+	fmt.Fprintln(w, "tunnels:", len(graph.tunnels()))
+	fmt.Fprintln(w, "start:", c.Start)
+	fmt.Fprintln(w, "end:", c.End)
+	report := c.Validate()
+	fmt.Fprintln(w, "max degree:", report.Metrics.MaxDegree)
+	for _, warning := range report.Warnings {
+		fmt.Fprintln(w, "warning:", warning.Message)
+	}
+
+	shortest := graph.distance(start, end)
+	if shortest < 0 {
+		fmt.Fprintln(w, "shortest path: none")
+		return
+	}
+	fmt.Fprintln(w, "shortest path:", shortest, "tunnels")
+
+	// A room that is both start and end cannot be cut off from itself
+	if start == end {
+		fmt.Fprintln(w, "min cut: not applicable")
+		return
+	}
+
+	size, rooms, direct := graph.minCut(start, end)
+	parts := graph.PathNames(rooms)
+	if direct {
+		parts = append(parts, c.Start+"-"+c.End)
+	}
+	fmt.Fprintf(w, "min cut: %d (%s)\n", size, strings.Join(parts, ", "))
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"lem2/pkg/parser"
+)
+
+// TestInfo checks the min cut line of lem-in info, which makes no sense
+// for a room that is both start and end
+func TestInfo(t *testing.T) {
+	for file, want := range map[string]string{
+		"testdata/bench/small.map":       "min cut: 1 (4)\n",
+		"testdata/edge/start-is-end.map": "min cut: not applicable\n",
+	} {
+		c, err := parser.ParseInput(file, parser.Strict01Edu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		writeInfo(&out, c)
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("%s: got\n%s\nwant a line with %q", file, out.String(), want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/convert"
+)
+
+// jsonResult is the document written by --json in place of the text
+// protocol. The colony uses the same layout as "lem-in convert -to json".
+type jsonResult struct {
+	Colony json.RawMessage `json:"colony"`
+	Stage  string          `json:"stage,omitempty"`
+	Paths  []pathUse       `json:"paths"`
+	Turns  [][]namedMove   `json:"turns"`
+	Ants   []trajectory    `json:"ants"`
+	Stats  Stats           `json:"stats"`
+}
+
+// pathUse is a route through the colony and the number of ants taking it
+type pathUse struct {
+	Rooms []string `json:"rooms"`
+	Ants  int      `json:"ants"`
+}
+
+// paths lists the distinct routes the ants follow, from start to end, in
+// the order the first ant on each route leaves
+func (s *Solution) paths() []pathUse {
+	paths, _ := s.antRoutes()
+	return paths
+}
+
+// antRoutes is paths along with the index in paths of the route of every
+// ant that moves
+func (s *Solution) antRoutes() ([]pathUse, map[int]int) {
+	route := make(map[int][]string)
+	var order []int
+	names := s.roomNames()
+	for i, moves := range s.Turns {
+		for j, move := range moves {
+			if _, ok := route[move.Ant]; !ok {
+				start, ok := s.Graph.entrances[move.Room]
+				if !ok {
+					start = s.Start
+				}
+				route[move.Ant] = []string{s.Graph.Name(start)}
+				order = append(order, move.Ant)
+			}
+			route[move.Ant] = append(route[move.Ant], names[i][j])
+		}
+	}
+
+	paths := []pathUse{}
+	index := make(map[string]int)
+	of := make(map[int]int, len(order))
+	for _, ant := range order {
+		key := strings.Join(route[ant], " ")
+		i, ok := index[key]
+		if !ok {
+			i = len(paths)
+			index[key] = i
+			paths = append(paths, pathUse{Rooms: route[ant]})
+		}
+		paths[i].Ants++
+		of[ant] = i
+	}
+	return paths, of
+}
+
+// trajectory is the sequence of rooms of one ant, from the start room at
+// turn 0 to the end room, so tools can follow ants without replaying the
+// turns
+type trajectory struct {
+	Ant   int     `json:"ant"`
+	Rooms []visit `json:"rooms"`
+}
+
+// visit is a room and the turn an ant entered it
+type visit struct {
+	Room string `json:"room"`
+	Turn int    `json:"turn"`
+}
+
+// trajectories lists the rooms of every one of the ants in order of ant
+// number; ants that never move only have the start room
+func (s *Solution) trajectories(ants int) []trajectory {
+	out := make([]trajectory, ants)
+	for ant := 1; ant <= ants; ant++ {
+		out[ant-1].Ant = antNumber(ant)
+	}
+	names := s.roomNames()
+	for i, moves := range s.Turns {
+		for j, move := range moves {
+			t := &out[move.Ant-1]
+			if len(t.Rooms) == 0 {
+				start, ok := s.Graph.entrances[move.Room]
+				if !ok {
+					start = s.Start
+				}
+				t.Rooms = append(t.Rooms, visit{Room: s.Graph.Name(start)})
+			}
+			t.Rooms = append(t.Rooms, visit{Room: names[i][j], Turn: i + 1})
+		}
+	}
+	for i := range out {
+		if len(out[i].Rooms) == 0 {
+			out[i].Rooms = []visit{{Room: s.Graph.Name(s.Start)}}
+		}
+	}
+	return out
+}
+
+// writeJSON writes the colony, the paths, the moves, the trajectory of
+// every ant and the statistics of a solution as one JSON document
+func writeJSON(w io.Writer, c *colony.Colony, s *Solution) error {
+	encoded, err := convert.ToJSON(c)
+	if err != nil {
+		return err
+	}
+	result := jsonResult{
+		Colony: encoded,
+		Stage:  s.Stage,
+		Paths:  s.paths(),
+		Turns:  s.namedTurns(),
+		Ants:   s.trajectories(c.Ants),
+		Stats:  s.stats(),
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
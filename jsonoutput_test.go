@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"lem2/pkg/parser"
+)
+
+// TestTrajectories checks that every ant goes from a start room at turn 0
+// to an end room, one turn or more per room, and that the trajectories
+// hold every move of the plan
+func TestTrajectories(t *testing.T) {
+	for _, file := range []string{"bench/small.map", "bench/medium.map", "edge/multiple-terminals.map"} {
+		c, err := parser.ParseInput("testdata/"+file, parser.Lenient)
+		if err != nil {
+			t.Fatal(err)
+		}
+		solution, err := solveColony(c, []chainStage{{name: "auto"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		moves := 0
+		for _, m := range solution.Turns {
+			moves += len(m)
+		}
+		trajectories := solution.trajectories(c.Ants)
+		if len(trajectories) != c.Ants {
+			t.Fatalf("%s: got %d trajectories for %d ants", file, len(trajectories), c.Ants)
+		}
+		for i, tr := range trajectories {
+			first, last := tr.Rooms[0], tr.Rooms[len(tr.Rooms)-1]
+			if tr.Ant != i+1 || !c.IsStart(first.Room) || first.Turn != 0 || !c.IsEnd(last.Room) {
+				t.Errorf("%s: ant %d goes from %+v to %+v", file, tr.Ant, first, last)
+			}
+			for j := 1; j < len(tr.Rooms); j++ {
+				if tr.Rooms[j].Turn <= tr.Rooms[j-1].Turn {
+					t.Errorf("%s: ant %d enters %+v after %+v", file, tr.Ant, tr.Rooms[j], tr.Rooms[j-1])
+				}
+			}
+			moves -= len(tr.Rooms) - 1
+		}
+		if moves != 0 {
+			t.Errorf("%s: %d moves are missing from the trajectories", file, moves)
+		}
+	}
+}
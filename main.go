@@ -1,89 +1,584 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"slices"
 	"sort"
+	"strings"
+	"time"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+	"lem2/pkg/pathfinder"
 )
 
+// Graph stores rooms by integer ID; names are only used when building the
+// graph and when printing results.
 type Graph struct {
-	vertices map[string][]string
+	names     []string             // room name per ID
+	index     map[string]int       // room ID per name
+	vertices  [][]int              // neighbors per room ID
+	hubs      map[int]map[int]bool // neighbor sets of rooms with many tunnels
+	exits     map[int]int          // with several end rooms merged into one: the end room entered from each room next to one
+	entrances map[int]int          // with several start rooms merged into one: the start room left for each room next to one
+	oneWay    map[[2]int]bool      // directed tunnels by their rooms, from first; vertices still list both directions
+	zones     []colony.Zone        // groups of rooms reported together by --stats and --visualize
 }
 
 func NewGraph() *Graph {
-	return &Graph{vertices: make(map[string][]string)}
+	return &Graph{index: make(map[string]int), hubs: make(map[int]map[int]bool)}
+}
+
+// AddRoom returns the ID of the named room, adding it if needed
+func (g *Graph) AddRoom(name string) int {
+	if id, ok := g.index[name]; ok {
+		return id
+	}
+	g.index[name] = len(g.names)
+	g.names = append(g.names, name)
+	g.vertices = append(g.vertices, nil)
+	return len(g.names) - 1
+}
+
+// ID returns the ID of the named room
+func (g *Graph) ID(name string) (int, bool) {
+	id, ok := g.index[name]
+	return id, ok
+}
+
+// Name returns the name of a room
+func (g *Graph) Name(id int) string {
+	return g.names[id]
+}
+
+// PathNames returns the room names along a path
+func (g *Graph) PathNames(path []int) []string {
+	names := make([]string, len(path))
+	for i, id := range path {
+		names[i] = g.names[id]
+	}
+	return names
 }
 
 func (g *Graph) AddEdge(start, end string) {
-	g.vertices[start] = append(g.vertices[start], end)
-	g.vertices[end] = append(g.vertices[end], start) // For undirected graph
+	a, b := g.AddRoom(start), g.AddRoom(end)
+	g.addNeighbor(a, b)
+	g.addNeighbor(b, a) // For undirected graph
 }
 
 // FindAllPaths finds all paths from start to end
-func (g *Graph) FindAllPaths(start, end string) [][]string {
-	var paths [][]string
-	var dfs func(current string, visited map[string]bool, path []string)
+func (g *Graph) FindAllPaths(ctx context.Context, start, end int) ([][]int, error) {
+	return g.FindPaths(ctx, start, end, 0)
+}
 
-	dfs = func(current string, visited map[string]bool, path []string) {
-		if current == end {
-			// Add the completed path
-			paths = append(paths, append([]string{}, path...))
-			return
+// FindPaths finds paths from start to end, stopping after limit paths
+// when limit is positive. It fails with ErrNoPath when there is none, with
+// an error matching ErrLimitExceeded when the search limits are hit, and
+// with the error of ctx, along with the paths found so far, when it is
+// cancelled. Complete results are kept in the plan cache when there is one.
+func (g *Graph) FindPaths(ctx context.Context, start, end, limit int) ([][]int, error) {
+	var paths [][]int
+	var err error
+	key := pathKey{}
+	cached := false
+	if cache != nil {
+		key = pathKey{g.topology(start, end), limit}
+		if paths, cached = cache.pathSet(key); cached {
+			explainLog.Printf("cache: reusing %d paths", len(paths))
+		}
+	}
+	if !cached {
+		paths, err = g.searchPaths(ctx, start, end, limit)
+		if err != nil {
+			return paths, err
+		}
+		if cache != nil {
+			cache.storePathSet(key, paths)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, ErrNoPath
+	}
+	return paths, nil
+}
+
+// Limits of the path search: paths with more than dfsMaxDepth tunnels are
+// skipped (--dfs-max-depth), and the search fails with ErrLimitExceeded
+// after entering dfsMaxVisits rooms (--dfs-max-visits). Zero means no limit.
+var dfsMaxDepth, dfsMaxVisits int
+
+var errDFSVisits error = pathfinder.LimitError("path search visited too many rooms")
+
+// searchPaths runs the DFS behind FindPaths. It keeps its own stack rather
+// than recursing, so a long chain of rooms cannot exhaust the goroutine
+// stack: path holds the rooms of the current branch and next[i] the index
+// of the next neighbor of path[i] to try.
+func (g *Graph) searchPaths(ctx context.Context, start, end, limit int) ([][]int, error) {
+	var paths [][]int
+	routes := g.routes(start, end)
+	visited := make([]bool, len(g.names))
+	path, next := []int{start}, []int{0}
+	visited[start] = true
+	visits := 0
+
+	for len(path) > 0 && (limit <= 0 || len(paths) < limit) {
+		top := len(path) - 1
+		current := path[top]
+		if current == end || next[top] == len(routes[current]) {
+			if current == end {
+				paths = append(paths, append([]int{}, path...))
+				status.paths.Add(1)
+			}
+			visited[current] = false
+			path, next = path[:top], next[:top]
+			continue
 		}
 
-		visited[current] = true
+		neighbor := routes[current][next[top]]
+		next[top]++
+		if visited[neighbor] || dfsMaxDepth > 0 && len(path) > dfsMaxDepth {
+			continue
+		}
 
-		for _, neighbor := range g.vertices[current] {
-			if !visited[neighbor] {
-				dfs(neighbor, visited, append(path, neighbor))
+		// Checking the context is not free, so only do it now and then
+		if visits++; visits%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return paths, err
 			}
 		}
+		if dfsMaxVisits > 0 && visits > dfsMaxVisits {
+			return paths, errDFSVisits
+		}
+		visited[neighbor] = true
+		path, next = append(path, neighbor), append(next, 0)
+	}
+	return paths, nil
+}
+
+// Move is a single ant entering a room during a turn
+type Move struct {
+	Ant  int
+	Room int
+}
 
-		visited[current] = false
+// PrintSchedule prints a precomputed schedule step by step, as the built-in
+// example does when no map is given
+func PrintSchedule(g *Graph, turns [][]Move) {
+	for i, moves := range turns {
+		fmt.Printf("\nStep %d:\n", i+1)
+		for _, move := range moves {
+			fmt.Printf("Ant %s moves to %s\n", antLabel(move.Ant), g.Name(move.Room))
+		}
 	}
+}
 
-	dfs(start, make(map[string]bool), []string{start})
-	return paths
+// ScheduleAnts distributes ants across paths and computes when each one
+// moves. Paths may share rooms: every ant reserves the rooms and tunnels it
+// uses turn by turn, so partially overlapping paths are used whenever they
+// still let an ant arrive sooner than waiting for a disjoint one.
+func ScheduleAnts(paths [][]int, ants int) [][]Move {
+	return scheduleWithin(paths, ants, 0, 0)
 }
 
-func SimulateAnts(paths [][]string, ants int) {
-	// Sort paths by length (shortest first)
-	sort.Slice(paths, func(i, j int) bool {
-		return len(paths[i]) < len(paths[j])
+// scheduleWithin is ScheduleAnts with two limits, unlimited when zero: no
+// ant may arrive after turn budget, and no room other than start and end
+// may see more than peak ants. It returns nil when some ant cannot be
+// placed within them.
+func scheduleWithin(paths [][]int, ants, budget, peak int) [][]Move {
+	// Sort paths by length (shortest first). Paths of the same length are
+	// ordered by their room IDs, which follow the room names, so the plan
+	// does not depend on the order in which the paths were found.
+	sort.SliceStable(paths, func(i, j int) bool {
+		return pathLess(paths[i], paths[j])
 	})
 
-	// Distribute ants across paths
-	assignments := make([][]int, len(paths))
+	table := closedSlots.clone()
+	exits := newExitCounter(paths[0][len(paths[0])-1])
+	spawns := newSpawnCounter(paths[0][0])
+	zones := newZoneCounter()
+	var turns [][]Move
+
+	// Reservations are only ever added, so the earliest departure on a
+	// path never moves back and each search resumes where the last ended
+	earliest := make([]int, len(paths))
+	load := make(map[int]int) // ants through every room, with a peak
+
 	for i := 0; i < ants; i++ {
-		assignments[i%len(paths)] = append(assignments[i%len(paths)], i+1)
+		// Pick the path on which this ant arrives first; shorter paths win ties
+		best := -1
+		for p, candidate := range paths {
+			if peak > 0 && slices.ContainsFunc(candidate[1:len(candidate)-1], func(room int) bool { return load[room] >= peak }) {
+				continue
+			}
+			earliest[p] = table.earliest(candidate, earliest[p])
+			if budget > 0 && earliest[p]+len(candidate)-1 > budget {
+				continue
+			}
+			if best < 0 || earliest[p]+len(candidate) < earliest[best]+len(paths[best]) {
+				best = p
+			}
+		}
+		if best < 0 {
+			return nil
+		}
+		path, depart := paths[best], earliest[best]
+		if peak > 0 {
+			for _, room := range path[1 : len(path)-1] {
+				load[room]++
+			}
+		}
+		table.reserve(path, depart)
+		exits.arrive(table, depart+len(path)-1)
+		spawns.leave(table, depart+1)
+
+		for pos := 1; pos < len(path); pos++ {
+			turn := depart + pos
+			for len(turns) < turn {
+				turns = append(turns, nil)
+			}
+			turns[turn-1] = append(turns[turn-1], Move{Ant: i + 1, Room: path[pos]})
+			zones.occupy(table, path[pos], turn)
+		}
 	}
 
-	// Simulate movement
-	step := 0
-	for {
-		step++
-		fmt.Printf("\nStep %d:\n", step)
-		moving := false
+	return turns
+}
 
-		for i, path := range paths {
-			for j, ant := range assignments[i] {
-				pos := step - j - 1 // Calculate the position of the ant along the path
+// pathLess orders paths by length, then room by room by ID
+func pathLess(a, b []int) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return slices.Compare(a, b) < 0
+}
 
-				// Check if the position is valid
-				if pos >= 0 && pos < len(path) {
-					moving = true
-					fmt.Printf("Ant %d moves to %s\n", ant, path[pos])
-				}
+// turnsFromTrajectories converts per-ant room sequences, indexed by turn,
+// into per-turn moves. Ants are numbered in order of departure.
+func turnsFromTrajectories(trajectories [][]int) [][]Move {
+	departure := func(tr []int) int {
+		for t := 1; t < len(tr); t++ {
+			if tr[t] != tr[0] {
+				return t
 			}
 		}
+		return len(tr)
+	}
+	sort.SliceStable(trajectories, func(i, j int) bool {
+		return departure(trajectories[i]) < departure(trajectories[j])
+	})
 
-		// Stop if no ants are moving
-		if !moving {
-			break
+	var turns [][]Move
+	for ant, tr := range trajectories {
+		for t := 1; t < len(tr); t++ {
+			if tr[t] == tr[t-1] {
+				continue
+			}
+			for len(turns) < t {
+				turns = append(turns, nil)
+			}
+			turns[t-1] = append(turns[t-1], Move{Ant: ant + 1, Room: tr[t]})
 		}
 	}
+	return turns
+}
+
+// stdinPiped reports whether stdin is a pipe or file rather than a terminal
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "watch":
+			os.Exit(runWatch(os.Args[2:]))
+		case "diagnose":
+			os.Exit(runDiagnose(os.Args[2:]))
+		case "fmt":
+			os.Exit(runFmt(os.Args[2:]))
+		case "convert":
+			os.Exit(runConvert(os.Args[2:]))
+		case "info":
+			os.Exit(runInfo(os.Args[2:]))
+		case "compose":
+			os.Exit(runCompose(os.Args[2:]))
+		case "generate":
+			os.Exit(runGenerate(os.Args[2:]))
+		case "replay":
+			os.Exit(runReplay(os.Args[2:]))
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		case "baseline":
+			os.Exit(runBaseline(os.Args[2:]))
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		case "stress":
+			os.Exit(runStress(os.Args[2:]))
+		case "tune":
+			os.Exit(runTune(os.Args[2:]))
+		case "verify":
+			os.Exit(runVerify(os.Args[2:]))
+		}
+	}
+
+	algo := flag.String("algo", "dfs", "scheduling algorithm: "+solverNames())
+	strategy := flag.String("strategy", "", "choose the paths with a path-selection strategy and schedule the ants on them, instead of --algo: "+strings.Join(pathfinder.Names(), ", "))
+	explain := flag.Bool("explain", false, "explain algorithm decisions on stderr")
+	chain := flag.String("chain", "", "fallback chain of algorithms with time budgets, e.g. exact@5s,cbs@5s,dfs")
+	flag.DurationVar(&solveTimeout, "timeout", 0, "stop searching for paths after this long, e.g. 5s, and use the best paths found so far (0: no limit)")
+	mmap := flag.Bool("mmap", false, "read the map through a memory mapping (for very large maps)")
+	throughput := flag.Bool("throughput", false, "draw per-turn moves and arrivals on stderr")
+	stats := flag.Bool("stats", false, "print solution statistics on stderr: turns, solve time, paths and ants per path, arrivals and busiest rooms")
+	allErrors := flag.Bool("all-errors", false, "report every invalid line of the map instead of stopping at the first")
+	verboseErrors := flag.Bool("verbose-errors", false, "explain a map error on stderr: its line number, the line itself and the rule it breaks")
+	templateFile := flag.String("template", "", "render the moves with a Go text/template file instead of the standard output")
+	flag.BoolVar(&dynamicAssign, "dynamic-assign", false, "let ants switch paths where paths share rooms, reporting the turns saved")
+	savePlan := flag.String("save-plan", "", "also write the plan to this file in the binary plan format")
+	savePaths := flag.String("save-paths", "", "also write every path between start and end to this file in the binary plan format")
+	flag.StringVar(&corpusDir, "corpus", "", "save maps on which a solver produces an invalid plan to this directory")
+	manifestFile := flag.String("manifest", "", "write a JSON manifest of the inputs, flags, build and output hash of the run to this file")
+	rulesFile := flag.String("rules", "", "close rooms in some turns with a Go text/template rule file")
+	heuristicsFile := flag.String("heuristics", "", "load the thresholds of the auto and bounded solvers from this JSON profile")
+	autoExactNodes := flag.Int("auto-exact-nodes", 0, "auto uses exact up to this time-expanded network size (0: from the profile)")
+	autoPaths := flag.Int("auto-paths", 0, "auto uses dfs below this many paths and flow otherwise (0: from the profile)")
+	boundedPaths := flag.Int("bounded-paths", 0, "number of paths bounded searches for (0: from the profile)")
+	flag.StringVar(&objective, "objective", objectiveTurns, "what the plan minimizes: turns, or spread (the peak of ant-turns in any room, within --turn-budget)")
+	flag.IntVar(&turnBudget, "turn-budget", 0, "turns a spread plan may take (0: as many as the fastest plan)")
+	flag.IntVar(&spawnRate, "spawn-rate", 0, "at most this many ants leave the start room per turn (0: no limit)")
+	flag.IntVar(&exitCapacity, "exit-capacity", 0, "at most this many ants enter the end room per turn (0: no limit)")
+	flag.IntVar(&antBase, "ant-base", 1, "number printed for the first ant, e.g. 0 for zero-based IDs")
+	flag.IntVar(&antWidth, "ant-width", 0, "zero-pad printed ant numbers to this many digits")
+	flag.StringVar(&moveLayout, "move-layout", layoutTurn, "text output layout: turn (one line per turn) or line (one move per line)")
+	flag.BoolVar(&outputHeader, "header", false, "add comment lines to the text output naming the solver build, algorithm, turns and paths")
+	framesOutput := flag.Bool("frames", false, "write the scene and then the ant positions after every turn as JSON Lines, for external renderers, instead of the text output")
+	flag.BoolVar(&outputCertificate, "certificate", false, "add comment lines to the text output with the ants and length of every path and the closed-form number of turns")
+	jsonOutput := flag.Bool("json", false, "write the colony, paths, moves and statistics as one JSON document instead of the text output")
+	rawEcho := flag.Bool("raw-echo", false, "echo the map byte for byte, keeping line endings, instead of line by line")
+	flag.IntVar(&dfsMaxDepth, "dfs-max-depth", 0, "skip paths with more than this many tunnels in the path search (0: no limit)")
+	flag.IntVar(&dfsMaxVisits, "dfs-max-visits", 0, "give up the path search after entering this many rooms (0: no limit)")
+	visualize := flag.Bool("visualize", false, "replay the moves as an animation in the terminal instead of printing them")
+	scrub := flag.Bool("scrub", false, "step through the moves in the terminal, reading commands such as n, p, g <turn> and m from stdin")
+	frameDelay := flag.Duration("frame-delay", 500*time.Millisecond, "time between the frames of --visualize and of playing with --scrub")
+	noOutput := flag.Bool("no-output", false, "plan and check the moves without printing them, reporting parse and plan times on stderr")
+	profileName := flag.String("profile", "strict", "map format profile: "+strings.Join(parser.ProfileNames(), ", ")+
+		"; lenient accepts tunnel capacities such as \"a-b 3\", which are checked by verify but ignored by the schedulers, which send one ant per tunnel per turn")
+	flag.Parse()
+
+	if *explain {
+		explainLog.SetOutput(os.Stderr)
+	}
+
+	stages, err := solverStages(*algo, *chain)
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+	if *strategy != "" {
+		if *chain != "" {
+			fmt.Println("ERROR: --strategy and --chain cannot be combined")
+			os.Exit(1)
+		}
+		if objective == objectiveSpread {
+			fmt.Println("ERROR: --strategy and --objective spread cannot be combined")
+			os.Exit(1)
+		}
+		if _, err := pathfinder.Lookup(*strategy); err != nil {
+			fmt.Println("ERROR:", err)
+			os.Exit(1)
+		}
+	}
+	profile, err := parser.LookupProfile(*profileName)
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+	profile.KeepRaw = *rawEcho
+	// Solving needs a path, so a map without one fails right after parsing
+	profile.RequirePath = true
+	if *jsonOutput && *templateFile != "" {
+		fmt.Println("ERROR: --json and --template cannot be combined")
+		os.Exit(1)
+	}
+	if moveLayout != layoutTurn && moveLayout != layoutLine {
+		fmt.Printf("ERROR: unknown move layout %q (want %s or %s)\n", moveLayout, layoutTurn, layoutLine)
+		os.Exit(1)
+	}
+	if objective != objectiveTurns && objective != objectiveSpread {
+		fmt.Printf("ERROR: unknown objective %q (want %s or %s)\n", objective, objectiveTurns, objectiveSpread)
+		os.Exit(1)
+	}
+	if turnBudget < 0 {
+		fmt.Println("ERROR: --turn-budget must not be negative")
+		os.Exit(1)
+	}
+	if antBase < 0 || antWidth < 0 {
+		fmt.Println("ERROR:", errAntNumbering)
+		os.Exit(1)
+	}
+	if *rulesFile != "" {
+		if turnRules, err = loadRules(*rulesFile); err != nil {
+			fmt.Println("ERROR:", err)
+			os.Exit(1)
+		}
+	}
+
+	if *heuristicsFile != "" {
+		if heuristics, err = loadHeuristics(*heuristicsFile); err != nil {
+			fmt.Println("ERROR:", err)
+			os.Exit(1)
+		}
+	}
+	for _, override := range []struct{ value, field *int }{
+		{autoExactNodes, &heuristics.AutoExactNodes},
+		{autoPaths, &heuristics.AutoPaths},
+		{boundedPaths, &heuristics.BoundedPaths},
+	} {
+		if *override.value > 0 {
+			*override.field = *override.value
+		}
+	}
+	if err := heuristics.check(); err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+
+	dumpStatusOnSignal()
+
+	// Without a map argument, a map piped into stdin is read from there
+	file := flag.Arg(0)
+	if file == "" && stdinPiped() {
+		file = "-"
+	}
+
+	if *scrub && file == "-" {
+		fmt.Println("ERROR: --scrub reads its commands from stdin, so the map must be given as a file")
+		os.Exit(1)
+	}
+
+	if file != "" {
+		status.setPhase("parsing %s", file)
+		parse := parser.ParseInput
+		if *mmap {
+			parse = parser.ParseInputMmap
+		}
+		// Stdin can only be read once, so --all-errors reuses its lines
+		var lines []string
+		if file == "-" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				fmt.Println("ERROR:", err)
+				os.Exit(1)
+			}
+			lines, _ = parser.ReadLines(bytes.NewReader(data))
+			parse = func(_ string, profile parser.Profile) (*colony.Colony, error) {
+				return parser.ParseBytes(data, profile)
+			}
+		}
+		started := time.Now()
+		c, err := parse(file, profile)
+		if err != nil {
+			fmt.Println(err)
+			if *verboseErrors {
+				reportVerboseError(file, err)
+			}
+			if *allErrors {
+				reportAllErrors(file, lines, profile)
+			}
+			os.Exit(1)
+		}
+		parsed := time.Now()
+		var solution *Solution
+		if *strategy != "" {
+			solution, err = solveStrategy(c, *strategy)
+		} else {
+			solution, err = solveColony(c, stages)
+		}
+		if err != nil {
+			fmt.Println("ERROR:", err)
+			os.Exit(1)
+		}
+		planned := time.Now()
+		if *chain != "" {
+			fmt.Fprintln(os.Stderr, "chain: plan produced by", solution.Stage)
+		}
+		if used, cut, ok := solution.checkCut(c.Ants); !ok {
+			paths := "paths"
+			if used == 1 {
+				paths = "path"
+			}
+			fmt.Fprintf(os.Stderr, "warning: the plan uses %d %s but the colony allows %d in parallel\n", used, paths, cut)
+		}
+		var out io.Writer = os.Stdout
+		output := sha256.New()
+		if *manifestFile != "" {
+			out = io.MultiWriter(os.Stdout, output)
+		}
+		if *noOutput {
+			// Formatting and printing millions of moves would dwarf the
+			// time spent planning, so only the timings are reported
+			fmt.Fprintf(os.Stderr, "no-output: %d turns; parse %v, plan %v\n", len(solution.Turns),
+				parsed.Sub(started).Round(time.Millisecond), planned.Sub(parsed).Round(time.Millisecond))
+		} else if *scrub {
+			if err := solution.scrub(os.Stdin, out, c, c.Ants, *frameDelay); err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR:", err)
+				os.Exit(1)
+			}
+		} else if *visualize {
+			if err := solution.visualize(out, c, c.Ants, *frameDelay); err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR:", err)
+				os.Exit(1)
+			}
+		} else if *framesOutput {
+			if err := writeFrames(out, c, solution); err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR:", err)
+				os.Exit(1)
+			}
+		} else if *jsonOutput {
+			if err := writeJSON(out, c, solution); err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR:", err)
+				os.Exit(1)
+			}
+		} else if *templateFile != "" {
+			tmpl, err := loadTemplate(*templateFile)
+			if err == nil {
+				err = writeTemplate(out, tmpl, c.Ants, solution)
+			}
+			if err != nil {
+				fmt.Println("ERROR:", err)
+				os.Exit(1)
+			}
+		} else if err := writeSolution(out, c, solution); err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+			os.Exit(1)
+		}
+		if *manifestFile != "" {
+			if err := newManifest(file, c.Input, solution).write(*manifestFile, output); err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR:", err)
+				os.Exit(1)
+			}
+		}
+		if err := savePlanFiles(c, solution, *savePlan, *savePaths); err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+			os.Exit(1)
+		}
+		if *throughput {
+			solution.writeThroughput(os.Stderr)
+		}
+		if *stats {
+			st := solution.stats()
+			st.SolveTime = planned.Sub(parsed)
+			st.write(os.Stderr)
+		}
+		return
+	}
+
 	graph := NewGraph()
 	graph.AddEdge("1", "3")
 	graph.AddEdge("1", "2")
@@ -93,9 +588,17 @@ func main() {
 	graph.AddEdge("5", "6")
 	graph.AddEdge("6", "7")
 
-	paths := graph.FindAllPaths("1", "7")
-	fmt.Println("Paths from start to end:", paths)
-
 	ants := 6
-	SimulateAnts(paths, ants)
+	start, _ := graph.ID("1")
+	end, _ := graph.ID("7")
+
+	turns, stage, err := runChain(graph, start, end, ants, stages)
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+	if *chain != "" {
+		fmt.Fprintln(os.Stderr, "chain: plan produced by", stage)
+	}
+	PrintSchedule(graph, turns)
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"hash"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// manifest records everything needed to reproduce a run (--manifest). The
+// solvers are deterministic, so the input, the flags and the build that
+// ran them determine the output, whose hash is kept for comparison.
+type manifest struct {
+	Args   []string          `json:"args"`
+	Flags  map[string]string `json:"flags"`
+	Input  manifestInput     `json:"input"`
+	Stage  string            `json:"stage"`
+	Turns  int               `json:"turns"`
+	Output string            `json:"output_sha256"`
+	Build  manifestBuild     `json:"build"`
+}
+
+type manifestInput struct {
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+}
+
+// manifestBuild identifies the binary, and with it the version of every
+// algorithm
+type manifestBuild struct {
+	GoVersion string `json:"go_version"`
+	Module    string `json:"module"`
+	Version   string `json:"version,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+}
+
+// newManifest describes the run so far. The input hash covers the lines as
+// parsed, which is also what gets echoed to the output.
+func newManifest(file string, input []string, s *Solution) *manifest {
+	m := &manifest{
+		Args:  os.Args[1:],
+		Flags: make(map[string]string),
+		Stage: s.Stage,
+		Turns: len(s.Turns),
+	}
+	flag.Visit(func(f *flag.Flag) {
+		m.Flags[f.Name] = f.Value.String()
+	})
+
+	sum := sha256.Sum256([]byte(strings.Join(input, "\n") + "\n"))
+	m.Input = manifestInput{File: file, SHA256: hex.EncodeToString(sum[:])}
+
+	m.Build = currentBuild()
+	return m
+}
+
+// currentBuild reads the build information embedded in the binary
+func currentBuild() manifestBuild {
+	build := manifestBuild{GoVersion: runtime.Version()}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		build.Module = info.Main.Path
+		build.Version = info.Main.Version
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				build.Revision = setting.Value
+			case "vcs.modified":
+				build.Modified = setting.Value == "true"
+			}
+		}
+	}
+	return build
+}
+
+// write stores the manifest with the hash of everything written to output
+func (m *manifest) write(file string, output hash.Hash) error {
+	m.Output = hex.EncodeToString(output.Sum(nil))
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,106 @@
+package main
+
+// minCut computes the minimum vertex cut between start and end: the
+// smallest set of rooms that disconnects them, which bounds how many ants
+// can travel in parallel. A direct start-end tunnel cannot be cut by
+// removing rooms, so it is reported separately and adds one to the size.
+func (g *Graph) minCut(start, end int) (size int, rooms []int, direct bool) {
+	n := len(g.names)
+	infinite := n + 1
+	net := newFlowNetwork(2 * n)
+
+	// Room r is split into 2r (in) and 2r+1 (out)
+	for room := 0; room < n; room++ {
+		capacity := 1
+		if room == start || room == end {
+			capacity = infinite
+		}
+		net.addEdge(2*room, 2*room+1, capacity)
+	}
+	for _, tunnel := range g.tunnels() {
+		a, b := tunnel[0], tunnel[1]
+		capacity := infinite
+		if a == start && b == end || a == end && b == start {
+			capacity = 1
+			direct = true
+		}
+		if g.leads(a, b) {
+			net.addEdge(2*a+1, 2*b, capacity)
+		}
+		if g.leads(b, a) {
+			net.addEdge(2*b+1, 2*a, capacity)
+		}
+	}
+
+	size = net.maxFlow(2*start, 2*end+1, infinite)
+
+	// The cut rooms are those whose in-node is still reachable from the
+	// start in the residual graph while their out-node is not
+	reached := make([]bool, 2*n)
+	reached[2*start] = true
+	queue := []int{2 * start}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, e := range net.head[u] {
+			if v := net.to[e]; net.cap[e] > 0 && !reached[v] {
+				reached[v] = true
+				queue = append(queue, v)
+			}
+		}
+	}
+	for room := 0; room < n; room++ {
+		if reached[2*room] && !reached[2*room+1] {
+			rooms = append(rooms, room)
+		}
+	}
+	return size, rooms, direct
+}
+
+// routes returns the number of distinct room sequences the ants follow
+func (s *Solution) routes() int {
+	return len(s.paths())
+}
+
+// checkCut compares the routes used by the plan with the minimum cut. It
+// reports false when there are enough ants to fill the cut, the plan uses
+// fewer routes, and more vertex-disjoint paths would take fewer turns by
+// the closed form (ants + Σ tunnels) / paths, rounded up, minus one: then
+// the plan leaves throughput unused. A plan that skips a path because it
+// is too long to help passes. An exit capacity below the cut is the real
+// limit on parallel routes. Zone capacities limit routes in ways the cut
+// does not show, so maps with them are not checked.
+func (s *Solution) checkCut(ants int) (used, cut int, ok bool) {
+	if s.Start == s.End || zoneLimits != nil {
+		return 0, 0, true
+	}
+	cut, _, _ = s.Graph.minCut(s.Start, s.End)
+	if exitCapacity > 0 {
+		cut = min(cut, exitCapacity)
+	}
+	if spawnRate > 0 {
+		cut = min(cut, spawnRate)
+	}
+	used = s.routes()
+	if ants < cut || used >= cut {
+		return used, cut, true
+	}
+	ok = true
+	s.Graph.disjointPathSets(s.Start, s.End, cut, func(paths [][]int) bool {
+		if len(paths) > used && closedFormTurns(paths, ants) < len(s.Turns) {
+			ok = false
+		}
+		return ok
+	})
+	return used, cut, ok
+}
+
+// closedFormTurns returns the turns taken by ants spread over the given
+// vertex-disjoint paths so that they all arrive together
+func closedFormTurns(paths [][]int, ants int) int {
+	total := ants
+	for _, path := range paths {
+		total += len(path) - 1
+	}
+	return (total+len(paths)-1)/len(paths) - 1
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"lem2/pkg/parser"
+)
+
+// TestCheckCut checks that a plan on one path is only flagged when the
+// second disjoint path would make it faster: never when that path is 10
+// rooms long, and always when it is as short as the first
+func TestCheckCut(t *testing.T) {
+	long := []string{"3", "##start", "s 0 0", "a 1 0", "##end", "e 2 0"}
+	for i := 1; i <= 10; i++ {
+		long = append(long, fmt.Sprintf("b%d %d 1", i, i))
+	}
+	long = append(long, "s-a", "a-e", "s-b1", "b10-e")
+	for i := 1; i < 10; i++ {
+		long = append(long, fmt.Sprintf("b%d-b%d", i, i+1))
+	}
+	short := []string{"3", "##start", "s 0 0", "a 1 0", "b 1 1", "##end", "e 2 0", "s-a", "a-e", "s-b", "b-e"}
+
+	for _, test := range []struct {
+		name  string
+		lines []string
+		ok    bool
+	}{{"long", long, true}, {"short", short, false}} {
+		c, err := parser.ParseLines(test.lines, parser.Strict01Edu)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		solution, err := solveColony(c, []chainStage{{name: "exact"}})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if test.name == "short" {
+			// Force every ant onto the first path
+			a, _ := solution.Graph.ID("a")
+			solution.Turns = ScheduleAnts([][]int{{solution.Start, a, solution.End}}, c.Ants)
+		}
+		if used, cut, ok := solution.checkCut(c.Ants); ok != test.ok {
+			t.Errorf("%s: plan on %d paths with a cut of %d: got ok %v, want %v", test.name, used, cut, ok, test.ok)
+		}
+	}
+}
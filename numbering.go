@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Ants are numbered from 1 internally. antBase (--ant-base) is the number
+// printed for the first ant and antWidth (--ant-width) zero-pads the
+// printed numbers, since visualizers and graders disagree on both.
+var (
+	antBase  = 1
+	antWidth int
+)
+
+var errAntNumbering = errors.New("--ant-base and --ant-width must not be negative")
+
+// antNumber returns the number printed for the internal ant number ant
+func antNumber(ant int) int {
+	return ant - 1 + antBase
+}
+
+// antLabel returns the printed, possibly zero-padded number of ant
+func antLabel(ant int) string {
+	return fmt.Sprintf("%0*d", antWidth, antNumber(ant))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+
+	"lem2/pkg/colony"
+)
+
+// PathSet is the first phase of solving a colony: the paths between start
+// and end. They only depend on the topology, so one path set serves any
+// number of ants.
+type PathSet struct {
+	Graph      *Graph
+	Start, End int
+	Paths      [][]int
+}
+
+// FindPathSet finds every path between the start and end rooms of c
+func FindPathSet(c *colony.Colony) (*PathSet, error) {
+	graph := graphFromColony(c)
+	start, _ := graph.ID(c.Start)
+	end, _ := graph.ID(c.End)
+
+	paths, err := graph.FindPaths(context.Background(), start, end, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &PathSet{Graph: graph, Start: start, End: end, Paths: paths}, nil
+}
+
+// AssignAnts is the second phase: it schedules n ants on the paths of ps
+// without repeating any graph work, so callers can vary n cheaply
+func AssignAnts(ps *PathSet, n int) *Solution {
+	// The scheduler sorts the paths it is given
+	paths := append([][]int(nil), ps.Paths...)
+	return &Solution{Graph: ps.Graph, Start: ps.Start, End: ps.End, Turns: schedule(ps.Graph, paths, n), Stage: "dfs"}
+}
@@ -0,0 +1,164 @@
+package colony
+
+import "slices"
+
+// Room is a room of the colony with its coordinates
+type Room struct {
+	Name     string
+	X, Y     int
+	Capacity int // ants the room holds at once, when more than one
+}
+
+// Tunnel connects two rooms. A directed tunnel only leads from From to To.
+type Tunnel struct {
+	From, To string
+	Directed bool
+}
+
+// Zone is a named group of rooms, such as a floor of a building, whose
+// occupancy is reported as a whole. A room belongs to one zone at most.
+type Zone struct {
+	Name     string
+	Rooms    []string
+	Capacity int // ants the zone holds at once, start and end rooms aside; 0 for no limit
+}
+
+// Colony is a parsed ant farm description
+type Colony struct {
+	Ants      int
+	Rooms     map[string]*Room
+	Start     string
+	End       string
+	Starts    []string // every start room when the map marks several, Start being the first; nil otherwise
+	Ends      []string // every end room when the map marks several, End being the first; nil otherwise
+	Tunnels   []Tunnel
+	Adjacency map[string][]string // neighbors of every room, kept in step with Tunnels by AddTunnel
+	Capacity  map[[2]string]int   // ants a tunnel takes per turn, when more than one, by its rooms in sorted order
+	OneWay    map[[2]string]bool  // tunnels open in one direction only, From first
+	Zones     []Zone              // groups of rooms, in the order the map gives them
+	Input     []string            // original lines, echoed before the moves
+	Raw       []byte              // the input exactly as read, when the parser was asked to keep it
+}
+
+func New() *Colony {
+	return &Colony{Rooms: make(map[string]*Room), Adjacency: make(map[string][]string)}
+}
+
+// AddTunnel appends a tunnel and records both rooms as neighbors, even
+// when the tunnel is directed. A directed tunnel whose reverse was added
+// before opens the pair both ways: the rooms are neighbors already, and
+// OneWay no longer lists the pair.
+func (c *Colony) AddTunnel(t Tunnel) {
+	c.Tunnels = append(c.Tunnels, t)
+	if t.Directed && c.OneWay[[2]string{t.To, t.From}] {
+		delete(c.OneWay, [2]string{t.To, t.From})
+		return
+	}
+	c.Adjacency[t.From] = append(c.Adjacency[t.From], t.To)
+	c.Adjacency[t.To] = append(c.Adjacency[t.To], t.From)
+	if t.Directed {
+		if c.OneWay == nil {
+			c.OneWay = make(map[[2]string]bool)
+		}
+		c.OneWay[[2]string{t.From, t.To}] = true
+	}
+}
+
+// Leads reports whether ants may cross the tunnel between neighbors from
+// and to in that direction, which only a directed tunnel from to to
+// forbids
+func (c *Colony) Leads(from, to string) bool {
+	return !c.OneWay[[2]string{to, from}]
+}
+
+// Neighbors returns the rooms connected to the named room by a tunnel
+func (c *Colony) Neighbors(name string) []string {
+	return c.Adjacency[name]
+}
+
+// StartRooms returns every start room, Start first
+func (c *Colony) StartRooms() []string {
+	if c.Starts != nil {
+		return c.Starts
+	}
+	return []string{c.Start}
+}
+
+// EndRooms returns every end room, End first
+func (c *Colony) EndRooms() []string {
+	if c.Ends != nil {
+		return c.Ends
+	}
+	return []string{c.End}
+}
+
+// IsStart reports whether ants may start in the named room
+func (c *Colony) IsStart(name string) bool {
+	return name == c.Start || slices.Contains(c.Starts, name)
+}
+
+// IsEnd reports whether ants may finish in the named room
+func (c *Colony) IsEnd(name string) bool {
+	return name == c.End || slices.Contains(c.Ends, name)
+}
+
+// TunnelCapacity returns how many ants may cross the tunnel between a and b
+// in one turn, 1 unless the map says otherwise
+func (c *Colony) TunnelCapacity(a, b string) int {
+	if n, ok := c.Capacity[tunnelKey(a, b)]; ok {
+		return n
+	}
+	return 1
+}
+
+// SetTunnelCapacity sets how many ants may cross the tunnel between a and
+// b in one turn
+func (c *Colony) SetTunnelCapacity(a, b string, n int) {
+	if n == 1 {
+		delete(c.Capacity, tunnelKey(a, b))
+		return
+	}
+	if c.Capacity == nil {
+		c.Capacity = make(map[[2]string]int)
+	}
+	c.Capacity[tunnelKey(a, b)] = n
+}
+
+// RoomCapacity returns how many ants may be in the named room at the end
+// of a turn, 1 unless the map says otherwise. The start and end rooms hold
+// every ant whatever their capacity.
+func (c *Colony) RoomCapacity(name string) int {
+	if room := c.Rooms[name]; room != nil && room.Capacity > 1 {
+		return room.Capacity
+	}
+	return 1
+}
+
+// ZoneOf returns the name of the zone of the named room, or "" when the
+// room is in none
+func (c *Colony) ZoneOf(name string) string {
+	for _, zone := range c.Zones {
+		if slices.Contains(zone.Rooms, name) {
+			return zone.Name
+		}
+	}
+	return ""
+}
+
+// ZoneCapacity returns how many ants the named zone holds at the end of a
+// turn, or 0 when it has no limit
+func (c *Colony) ZoneCapacity(zone string) int {
+	for _, z := range c.Zones {
+		if z.Name == zone {
+			return z.Capacity
+		}
+	}
+	return 0
+}
+
+func tunnelKey(a, b string) [2]string {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
@@ -0,0 +1,144 @@
+package colony
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Issue is a problem found by Validate. Room names the room it is about,
+// when there is one, so callers holding the input can point at its line.
+type Issue struct {
+	Message string `json:"message"`
+	Room    string `json:"room,omitempty"`
+}
+
+// Metrics summarizes the size of a colony
+type Metrics struct {
+	Ants      int `json:"ants"`
+	Rooms     int `json:"rooms"`
+	Tunnels   int `json:"tunnels"`
+	MaxDegree int `json:"max_degree"`
+	Shortest  int `json:"shortest_path"` // tunnels from a start to an end room, -1 when not connected
+}
+
+// Report is the result of Validate. Errors make the colony unsolvable;
+// warnings point at things that are unlikely to be what the author meant.
+type Report struct {
+	Errors   []Issue `json:"errors"`
+	Warnings []Issue `json:"warnings"`
+	Metrics  Metrics `json:"metrics"`
+}
+
+// OK reports whether the colony has no errors
+func (r *Report) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// Validate checks a colony however it was built, so the parser, the
+// linter and the server share one set of rules. Issues about rooms are
+// listed in order of room name.
+func (c *Colony) Validate() *Report {
+	r := &Report{Errors: []Issue{}, Warnings: []Issue{}}
+	errorf := func(room, format string, args ...any) {
+		r.Errors = append(r.Errors, Issue{Message: fmt.Sprintf(format, args...), Room: room})
+	}
+
+	if c.Ants <= 0 {
+		errorf("", "invalid number of ants %d", c.Ants)
+	}
+	for _, end := range []struct{ name, room string }{{"start", c.Start}, {"end", c.End}} {
+		switch {
+		case end.room == "":
+			errorf("", "no ##%s room", end.name)
+		case c.Rooms[end.room] == nil:
+			errorf(end.room, "%s room %s is not defined", end.name, end.room)
+		}
+	}
+	for _, t := range c.Tunnels {
+		for _, room := range []string{t.From, t.To} {
+			if c.Rooms[room] == nil {
+				errorf(room, "tunnel %s-%s uses unknown room %s", t.From, t.To, room)
+			}
+		}
+		if t.From == t.To {
+			errorf(t.From, "tunnel from %s to itself", t.From)
+		}
+	}
+	for _, zone := range c.Zones {
+		for _, room := range zone.Rooms {
+			if c.Rooms[room] == nil {
+				errorf(room, "zone %s uses unknown room %s", zone.Name, room)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(c.Rooms))
+	for name := range c.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		degree := len(c.Neighbors(name))
+		r.Metrics.MaxDegree = max(r.Metrics.MaxDegree, degree)
+		if degree == 0 {
+			r.Warnings = append(r.Warnings, Issue{Message: "room " + name + " has no tunnels", Room: name})
+		}
+	}
+
+	r.Metrics.Ants = c.Ants
+	r.Metrics.Rooms = len(c.Rooms)
+	r.Metrics.Tunnels = len(c.Tunnels)
+	r.Metrics.Shortest = c.distance()
+	if r.OK() && !c.Connected() {
+		r.Warnings = append(r.Warnings, Issue{Message: "start and end are not connected", Room: c.End})
+	}
+	return r
+}
+
+// Connected reports whether ants can reach an end room from a start room,
+// following the direction of one-way tunnels
+func (c *Colony) Connected() bool {
+	seen := make(map[string]bool)
+	queue := append([]string(nil), c.StartRooms()...)
+	for _, room := range queue {
+		seen[room] = true
+	}
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+		if c.IsEnd(room) {
+			return true
+		}
+		for _, next := range c.Neighbors(room) {
+			if !seen[next] && c.Leads(room, next) {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// distance returns the fewest tunnels from a start room to an end room,
+// or -1 when none is reachable
+func (c *Colony) distance() int {
+	dist := make(map[string]int)
+	queue := append([]string(nil), c.StartRooms()...)
+	for _, room := range queue {
+		dist[room] = 0
+	}
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+		if c.IsEnd(room) {
+			return dist[room]
+		}
+		for _, next := range c.Neighbors(room) {
+			if _, seen := dist[next]; !seen && c.Leads(room, next) {
+				dist[next] = dist[room] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,136 @@
+// Package compose builds colonies out of smaller ones, which makes it easy
+// to assemble large benchmark maps from a few hand-written building blocks.
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lem2/pkg/colony"
+)
+
+// Union returns a colony with the rooms and tunnels of both a and b. Rooms
+// with the same name are the same room, which is how two colonies are glued
+// together; they must have the same coordinates. The start and end rooms of
+// a are kept and the ants of both colonies are added up, since b's paths
+// run in parallel to a's when they share start and end. Tunnel capacities
+// and directions are kept; a tunnel in both colonies keeps the capacity it
+// has in a.
+func Union(a, b *colony.Colony) (*colony.Colony, error) {
+	c := colony.New()
+	c.Ants = a.Ants + b.Ants
+	c.Start, c.End = a.Start, a.End
+
+	seen := make(map[colony.Tunnel]bool)
+	for _, part := range []*colony.Colony{a, b} {
+		// Rooms are merged in order of name, so a conflict is always
+		// reported for the same room
+		names := make([]string, 0, len(part.Rooms))
+		for name := range part.Rooms {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			room := part.Rooms[name]
+			if existing, ok := c.Rooms[name]; ok {
+				if existing.X != room.X || existing.Y != room.Y {
+					return nil, fmt.Errorf("room %s is at %d,%d and %d,%d", name, existing.X, existing.Y, room.X, room.Y)
+				}
+				continue
+			}
+			c.Rooms[name] = &colony.Room{Name: name, X: room.X, Y: room.Y, Capacity: room.Capacity}
+		}
+		for _, tunnel := range part.Tunnels {
+			// A tunnel is a duplicate when it joins the same rooms, in the
+			// same order when it is directed
+			reverse := colony.Tunnel{From: tunnel.To, To: tunnel.From, Directed: tunnel.Directed}
+			if seen[tunnel] || !tunnel.Directed && seen[reverse] {
+				continue
+			}
+			seen[tunnel] = true
+			c.AddTunnel(tunnel)
+			c.SetTunnelCapacity(tunnel.From, tunnel.To, part.TunnelCapacity(tunnel.From, tunnel.To))
+		}
+	}
+	return c, nil
+}
+
+// Bridge connects a and b in series with a new tunnel from the room from of
+// a to the room to of b. The result goes from a's start to b's end; as every
+// ant crosses both colonies, it carries the larger of the two ant counts.
+func Bridge(a, b *colony.Colony, from, to string) (*colony.Colony, error) {
+	if a.Rooms[from] == nil {
+		return nil, fmt.Errorf("unknown room %s in the first colony", from)
+	}
+	if b.Rooms[to] == nil {
+		return nil, fmt.Errorf("unknown room %s in the second colony", to)
+	}
+
+	c, err := Union(a, b)
+	if err != nil {
+		return nil, err
+	}
+	c.Ants = max(a.Ants, b.Ants)
+	c.End = b.End
+	c.AddTunnel(colony.Tunnel{From: from, To: to})
+	return c, nil
+}
+
+// Replicate returns n copies of c side by side, sharing its start and end
+// rooms. Every other room of copy i is renamed with suffix followed by i and
+// moved to the right of the previous copy. The ants are multiplied by n.
+func Replicate(c *colony.Colony, n int, suffix string) (*colony.Colony, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid number of copies %d", n)
+	}
+	if suffix == "" || strings.ContainsAny(suffix, "- \t#") {
+		return nil, fmt.Errorf("invalid suffix %q", suffix)
+	}
+
+	minX, maxX := 0, 0
+	first := true
+	for _, room := range c.Rooms {
+		if first || room.X < minX {
+			minX = room.X
+		}
+		if first || room.X > maxX {
+			maxX = room.X
+		}
+		first = false
+	}
+	width := maxX - minX + 1
+
+	result := colony.New()
+	result.Start, result.End = c.Start, c.End
+	for i := 1; i <= n; i++ {
+		rename := func(name string) string {
+			if name == c.Start || name == c.End {
+				return name
+			}
+			return name + suffix + strconv.Itoa(i)
+		}
+
+		part := colony.New()
+		part.Ants = c.Ants
+		part.Start, part.End = c.Start, c.End
+		for name, room := range c.Rooms {
+			moved := &colony.Room{Name: rename(name), X: room.X + (i-1)*width, Y: room.Y, Capacity: room.Capacity}
+			if name == c.Start || name == c.End {
+				moved.X = room.X
+			}
+			part.Rooms[moved.Name] = moved
+		}
+		for _, tunnel := range c.Tunnels {
+			part.AddTunnel(colony.Tunnel{From: rename(tunnel.From), To: rename(tunnel.To), Directed: tunnel.Directed})
+			part.SetTunnelCapacity(rename(tunnel.From), rename(tunnel.To), c.TunnelCapacity(tunnel.From, tunnel.To))
+		}
+
+		var err error
+		if result, err = Union(result, part); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package compose_test
+
+import (
+	"fmt"
+	"strings"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/compose"
+	"lem2/pkg/convert"
+)
+
+// parse reads a map with the capacities and one-way tunnels compose keeps
+func parse(input string) *colony.Colony {
+	c, err := convert.Decode([]byte(input), "map")
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func ExampleUnion() {
+	a := parse("2\n##start\ns 0 0\nm 1 0\n##end\ne 2 0\ns-m 2\nm-e 2\n")
+	b := parse("1\n##start\ns 0 0\nn 1 1\n##end\ne 2 0\ns->n\nn-e\n")
+	c, err := compose.Union(a, b)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	lines, _ := convert.ToMap(c)
+	fmt.Println(strings.Join(lines, "\n"))
+	// Output:
+	// 3
+	// ##start
+	// s 0 0
+	// ##end
+	// e 2 0
+	// m 1 0
+	// n 1 1
+	// e-m 2
+	// e-n
+	// m-s 2
+	// s->n
+}
+
+func ExampleReplicate() {
+	c, err := compose.Replicate(parse("2\n##start\ns 0 0\nm 1 0 3\n##end\ne 2 0\ns-m\nm->e\n"), 2, "_")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	lines, _ := convert.ToMap(c)
+	fmt.Println(strings.Join(lines, "\n"))
+	// Output:
+	// 4
+	// ##start
+	// s 0 0
+	// ##end
+	// e 2 0
+	// m_1 1 0 3
+	// m_2 4 0 3
+	// m_1->e
+	// m_2->e
+	// m_1-s
+	// m_2-s
+}
@@ -0,0 +1,275 @@
+// Package convert translates colonies between the supported file formats:
+// the lem-in map format, JSON, Graphviz DOT and DIMACS max-flow.
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+)
+
+// Formats lists the output formats in the order they are documented
+var Formats = []string{"map", "json", "dot", "dimacs"}
+
+// jsonColony is the JSON representation of a colony
+type jsonColony struct {
+	Ants    int          `json:"ants"`
+	Start   string       `json:"start"`
+	End     string       `json:"end"`
+	Rooms   []jsonRoom   `json:"rooms"`
+	Tunnels []jsonTunnel `json:"tunnels"`
+	Zones   []jsonZone   `json:"zones,omitempty"`
+}
+
+type jsonRoom struct {
+	Name     string `json:"name"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	Capacity int    `json:"capacity,omitempty"` // ants at once, when more than one
+}
+
+type jsonTunnel struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Capacity int    `json:"capacity,omitempty"` // ants per turn, when more than one
+	Directed bool   `json:"directed,omitempty"` // ants only go from From to To
+}
+
+type jsonZone struct {
+	Name     string   `json:"name"`
+	Rooms    []string `json:"rooms"`
+	Capacity int      `json:"capacity,omitempty"` // ants at once, when limited
+}
+
+// sortedRooms returns the rooms ordered by name
+func sortedRooms(c *colony.Colony) []*colony.Room {
+	rooms := make([]*colony.Room, 0, len(c.Rooms))
+	for _, room := range c.Rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].Name < rooms[j].Name })
+	return rooms
+}
+
+// Encode writes c in the given format. The returned warnings describe
+// information that the format cannot represent.
+func Encode(c *colony.Colony, format string) ([]byte, []string, error) {
+	switch format {
+	case "map":
+		lines, err := ToMap(c)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []byte(strings.Join(lines, "\n") + "\n"), nil, nil
+	case "json":
+		data, err := ToJSON(c)
+		return data, nil, err
+	case "dot":
+		return []byte(ToDOT(c)), zoneWarnings(c, format), nil
+	case "dimacs":
+		return []byte(ToDIMACS(c)), append([]string{"dimacs: room coordinates are dropped"}, zoneWarnings(c, format)...), nil
+	}
+	return nil, nil, fmt.Errorf("unknown format %q", format)
+}
+
+// zoneWarnings warns that a format without zones drops those of c
+func zoneWarnings(c *colony.Colony, format string) []string {
+	if len(c.Zones) == 0 {
+		return nil
+	}
+	return []string{format + ": zones are dropped"}
+}
+
+// Decode reads a colony in the given format. Only the map and JSON formats
+// can be read back. Maps are read in the strict format, with tunnel
+// and room capacities, directed tunnels and zones, which the map and JSON
+// formats can express.
+func Decode(data []byte, format string) (*colony.Colony, error) {
+	switch format {
+	case "map":
+		profile := parser.Strict01Edu
+		profile.AllowTunnelCapacity = true
+		profile.AllowRoomCapacity = true
+		profile.AllowDirectedTunnels = true
+		profile.AllowZones = true
+		return parser.ParseLines(strings.Split(strings.TrimRight(string(data), "\n"), "\n"), profile)
+	case "json":
+		return FromJSON(data)
+	case "dot", "dimacs":
+		return nil, fmt.Errorf("reading %s is not supported", format)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// ToMap writes c in the canonical lem-in map format
+func ToMap(c *colony.Colony) ([]string, error) {
+	lines := []string{strconv.Itoa(c.Ants)}
+	for _, room := range sortedRooms(c) {
+		// A room that is both start and end gets both commands
+		if room.Name == c.Start {
+			lines = append(lines, "##start")
+		}
+		if room.Name == c.End {
+			lines = append(lines, "##end")
+		}
+		line := fmt.Sprintf("%s %d %d", room.Name, room.X, room.Y)
+		if n := c.RoomCapacity(room.Name); n != 1 {
+			line += " " + strconv.Itoa(n)
+		}
+		lines = append(lines, line)
+	}
+	for _, tunnel := range c.Tunnels {
+		line := tunnel.From + "-" + tunnel.To
+		if tunnel.Directed {
+			line = tunnel.From + "->" + tunnel.To
+		}
+		if n := c.TunnelCapacity(tunnel.From, tunnel.To); n != 1 {
+			line += " " + strconv.Itoa(n)
+		}
+		lines = append(lines, line)
+	}
+	for _, zone := range c.Zones {
+		name := zone.Name
+		if zone.Capacity > 0 {
+			name += ":" + strconv.Itoa(zone.Capacity)
+		}
+		lines = append(lines, "##zone "+name+" "+strings.Join(zone.Rooms, " "))
+	}
+	return parser.Format(lines)
+}
+
+func ToJSON(c *colony.Colony) ([]byte, error) {
+	out := jsonColony{Ants: c.Ants, Start: c.Start, End: c.End, Rooms: []jsonRoom{}, Tunnels: []jsonTunnel{}}
+	for _, room := range sortedRooms(c) {
+		r := jsonRoom{Name: room.Name, X: room.X, Y: room.Y}
+		if n := c.RoomCapacity(room.Name); n != 1 {
+			r.Capacity = n
+		}
+		out.Rooms = append(out.Rooms, r)
+	}
+	for _, tunnel := range c.Tunnels {
+		t := jsonTunnel{From: tunnel.From, To: tunnel.To, Directed: tunnel.Directed}
+		if n := c.TunnelCapacity(tunnel.From, tunnel.To); n != 1 {
+			t.Capacity = n
+		}
+		out.Tunnels = append(out.Tunnels, t)
+	}
+	for _, zone := range c.Zones {
+		out.Zones = append(out.Zones, jsonZone{Name: zone.Name, Rooms: zone.Rooms, Capacity: zone.Capacity})
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	return append(data, '\n'), err
+}
+
+// FromJSON reads a JSON colony after validating it against Schema
+func FromJSON(data []byte) (*colony.Colony, error) {
+	if err := ValidateJSON(data); err != nil {
+		return nil, err
+	}
+
+	var in jsonColony
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+
+	c := colony.New()
+	c.Ants, c.Start, c.End = in.Ants, in.Start, in.End
+	for _, room := range in.Rooms {
+		if _, exists := c.Rooms[room.Name]; exists {
+			return nil, fmt.Errorf("duplicate room %s", room.Name)
+		}
+		c.Rooms[room.Name] = &colony.Room{Name: room.Name, X: room.X, Y: room.Y}
+		if room.Capacity > 1 {
+			c.Rooms[room.Name].Capacity = room.Capacity
+		}
+	}
+	for _, tunnel := range in.Tunnels {
+		if c.Rooms[tunnel.From] == nil || c.Rooms[tunnel.To] == nil {
+			return nil, fmt.Errorf("tunnel %s-%s uses an unknown room", tunnel.From, tunnel.To)
+		}
+		c.AddTunnel(colony.Tunnel{From: tunnel.From, To: tunnel.To, Directed: tunnel.Directed})
+		if tunnel.Capacity > 1 {
+			c.SetTunnelCapacity(tunnel.From, tunnel.To, tunnel.Capacity)
+		}
+	}
+	for _, zone := range in.Zones {
+		for _, room := range zone.Rooms {
+			if c.Rooms[room] == nil {
+				return nil, fmt.Errorf("zone %s uses unknown room %s", zone.Name, room)
+			}
+			if other := c.ZoneOf(room); other != "" {
+				return nil, fmt.Errorf("room %s is in zones %s and %s", room, other, zone.Name)
+			}
+		}
+		c.Zones = append(c.Zones, colony.Zone{Name: zone.Name, Rooms: zone.Rooms, Capacity: zone.Capacity})
+	}
+	if c.Ants <= 0 || c.Rooms[c.Start] == nil || c.Rooms[c.End] == nil {
+		return nil, errors.New("ants, start and end are required")
+	}
+	return c, nil
+}
+
+// ToDOT writes c as an undirected Graphviz graph, keeping the coordinates
+// as fixed node positions. Directed tunnels get an arrow.
+func ToDOT(c *colony.Colony) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "graph colony {\n\tlabel=\"%d ants\";\n", c.Ants)
+	for _, room := range sortedRooms(c) {
+		attrs := fmt.Sprintf("pos=\"%d,%d!\"", room.X, room.Y)
+		switch room.Name {
+		case c.Start:
+			attrs += ", shape=doublecircle, xlabel=\"start\""
+		case c.End:
+			attrs += ", shape=doublecircle, xlabel=\"end\""
+		}
+		fmt.Fprintf(&b, "\t%q [%s];\n", room.Name, attrs)
+	}
+	for _, tunnel := range c.Tunnels {
+		if tunnel.Directed {
+			fmt.Fprintf(&b, "\t%q -- %q [dir=forward];\n", tunnel.From, tunnel.To)
+			continue
+		}
+		fmt.Fprintf(&b, "\t%q -- %q;\n", tunnel.From, tunnel.To)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ToDIMACS writes c as a DIMACS max-flow problem. Every tunnel gets its
+// capacity, 1 unless the map gives one, in both directions, or only in
+// its own for a directed tunnel. Room names and the number of ants are
+// kept in comment lines; coordinates are lost.
+func ToDIMACS(c *colony.Colony) string {
+	rooms := sortedRooms(c)
+	id := make(map[string]int, len(rooms))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "c ants %d\n", c.Ants)
+	for i, room := range rooms {
+		id[room.Name] = i + 1
+		fmt.Fprintf(&b, "c room %d %s\n", i+1, room.Name)
+	}
+	arcs := 2 * len(c.Tunnels)
+	for _, tunnel := range c.Tunnels {
+		if tunnel.Directed {
+			arcs--
+		}
+	}
+	fmt.Fprintf(&b, "p max %d %d\n", len(rooms), arcs)
+	fmt.Fprintf(&b, "n %d s\n", id[c.Start])
+	fmt.Fprintf(&b, "n %d t\n", id[c.End])
+	for _, tunnel := range c.Tunnels {
+		n := c.TunnelCapacity(tunnel.From, tunnel.To)
+		fmt.Fprintf(&b, "a %d %d %d\n", id[tunnel.From], id[tunnel.To], n)
+		if !tunnel.Directed {
+			fmt.Fprintf(&b, "a %d %d %d\n", id[tunnel.To], id[tunnel.From], n)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package convert
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Schema is the JSON Schema of the JSON colony format
+//
+//go:embed colony.schema.json
+var Schema []byte
+
+// schema is the subset of JSON Schema used by colony.schema.json
+type schema struct {
+	Type                 string             `json:"type"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	Minimum              *float64           `json:"minimum"`
+	MinLength            *int               `json:"minLength"`
+}
+
+var colonySchema = func() *schema {
+	var s schema
+	if err := json.Unmarshal(Schema, &s); err != nil {
+		panic(err)
+	}
+	return &s
+}()
+
+// ValidateJSON checks a JSON colony against Schema and returns one error
+// per problem, each naming the offending field, e.g. "rooms[3].x must be
+// integer"
+func ValidateJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	var problems []error
+	colonySchema.validate("", value, &problems)
+	return errors.Join(problems...)
+}
+
+func (s *schema) validate(path string, value interface{}, problems *[]error) {
+	name := path
+	if name == "" {
+		name = "document"
+	}
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, fmt.Errorf(name+" "+format, args...))
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be object")
+			return
+		}
+		for _, key := range s.Required {
+			if _, ok := object[key]; !ok {
+				fail("is missing required field %q", key)
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := strings.TrimPrefix(path+"."+key, ".")
+			if property, ok := s.Properties[key]; ok {
+				property.validate(child, object[key], problems)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*problems = append(*problems, fmt.Errorf("%s is not allowed", child))
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			fail("must be array")
+			return
+		}
+		for i, item := range array {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be string")
+			return
+		}
+		if s.MinLength != nil && len(str) < *s.MinLength {
+			fail("must not be empty")
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			fail("must be integer")
+			return
+		}
+		f, err := number.Float64()
+		if err != nil || f != math.Trunc(f) {
+			fail("must be integer")
+			return
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be boolean")
+		}
+	}
+}
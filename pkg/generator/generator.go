@@ -0,0 +1,368 @@
+// Package generator produces random colonies for benchmarks and stress
+// tests. Every model is deterministic for a given seed, and the parameters
+// used are recorded as comments at the top of the generated map so a file
+// can always be regenerated.
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Options selects a model and its parameters. Parameters that do not apply
+// to the chosen model are ignored.
+type Options struct {
+	Model string
+	Rooms int
+	Ants  int
+	Seed  int64
+
+	M    int     // ba: tunnels added with every new room; hub: routes into and out of the hub
+	K    int     // ws: neighbors of every room in the initial ring, even
+	Beta float64 // ws: probability of rewiring each ring tunnel
+
+	DisjointPaths int   // disjoint: number of vertex-disjoint paths, selects the model when set
+	PathLengths   []int // disjoint: tunnels per path, the last length repeats
+	Distractors   int   // disjoint: extra tunnels that add no new path
+
+	Tunnels int // gnm: total number of tunnels, selects the model when set
+}
+
+// topology is the graph produced by a model. Rooms are numbered from 0.
+type topology struct {
+	rooms      int
+	edges      [][2]int
+	start, end int
+}
+
+// model builds a topology from the options
+type model func(o Options, r *rand.Rand) (*topology, error)
+
+var models = map[string]model{
+	"ba":       barabasiAlbert,
+	"ws":       wattsStrogatz,
+	"disjoint": disjointPaths,
+	"hub":      hub,
+	"gnm":      gnm,
+}
+
+// Models returns the names of the available models
+func Models() []string {
+	var names []string
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Generate returns the lines of a colony built with the chosen model. Unless
+// the model places them, the start room is room 0 and the end room the one
+// farthest from it, so start and end are always connected.
+func Generate(o Options) ([]string, error) {
+	if o.DisjointPaths > 0 {
+		o.Model = "disjoint"
+	}
+	if o.Tunnels > 0 {
+		o.Model = "gnm"
+	}
+	build, ok := models[o.Model]
+	if !ok {
+		return nil, fmt.Errorf("unknown model %q (available: %s)", o.Model, strings.Join(Models(), ", "))
+	}
+	if o.Ants <= 0 {
+		return nil, fmt.Errorf("invalid number of ants %d", o.Ants)
+	}
+	t, err := build(o, rand.New(rand.NewSource(o.Seed)))
+	if err != nil {
+		return nil, err
+	}
+	if t.start == t.end {
+		return nil, fmt.Errorf("the %s graph leaves room 0 isolated, try another seed", o.Model)
+	}
+	return t.lines(o), nil
+}
+
+// header describes the options in the comment lines of the map
+func (o Options) header(rooms int) []string {
+	params := fmt.Sprintf("rooms=%d ants=%d", rooms, o.Ants)
+	switch o.Model {
+	case "ba", "hub":
+		params += fmt.Sprintf(" m=%d", o.M)
+	case "ws":
+		params += fmt.Sprintf(" k=%d beta=%g", o.K, o.Beta)
+	case "disjoint":
+		lengths := make([]string, len(o.PathLengths))
+		for i, length := range o.PathLengths {
+			lengths[i] = strconv.Itoa(length)
+		}
+		params += fmt.Sprintf(" paths=%d lengths=%s distractors=%d", o.DisjointPaths, strings.Join(lengths, ","), o.Distractors)
+	case "gnm":
+		params += fmt.Sprintf(" tunnels=%d", o.Tunnels)
+	}
+	return []string{
+		"# generated by lem-in generate",
+		fmt.Sprintf("# model=%s %s seed=%d", o.Model, params, o.Seed),
+	}
+}
+
+// lines writes the topology in the map format, laying the rooms out on a grid
+func (t *topology) lines(o Options) []string {
+	lines := []string{strconv.Itoa(o.Ants)}
+	lines = append(lines, o.header(t.rooms)...)
+
+	side := int(math.Ceil(math.Sqrt(float64(t.rooms))))
+	for room := 0; room < t.rooms; room++ {
+		switch room {
+		case t.start:
+			lines = append(lines, "##start")
+		case t.end:
+			lines = append(lines, "##end")
+		}
+		lines = append(lines, fmt.Sprintf("%s %d %d", roomName(room), room%side, room/side))
+	}
+	for _, e := range t.edges {
+		lines = append(lines, roomName(e[0])+"-"+roomName(e[1]))
+	}
+	return lines
+}
+
+func roomName(room int) string {
+	return "r" + strconv.Itoa(room)
+}
+
+// useFarthest makes room 0 the start and the room farthest from it the end
+func (t *topology) useFarthest() {
+	t.start = 0
+	t.end = t.farthest(0)
+}
+
+// farthest returns the room reachable from start with the largest distance
+func (t *topology) farthest(start int) int {
+	neighbors := make([][]int, t.rooms)
+	for _, e := range t.edges {
+		neighbors[e[0]] = append(neighbors[e[0]], e[1])
+		neighbors[e[1]] = append(neighbors[e[1]], e[0])
+	}
+
+	seen := make([]bool, t.rooms)
+	seen[start] = true
+	queue := []int{start}
+	last := start
+	for len(queue) > 0 {
+		last, queue = queue[0], queue[1:]
+		for _, next := range neighbors[last] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return last
+}
+
+// edgeSet keeps tunnels unique regardless of direction
+type edgeSet map[[2]int]bool
+
+func (s edgeSet) has(a, b int) bool {
+	return s[[2]int{min(a, b), max(a, b)}]
+}
+
+func (s edgeSet) add(a, b int) {
+	s[[2]int{min(a, b), max(a, b)}] = true
+}
+
+func (s edgeSet) remove(a, b int) {
+	delete(s, [2]int{min(a, b), max(a, b)})
+}
+
+// barabasiAlbert grows a scale-free graph: it starts from m+1 fully
+// connected rooms and connects every new room to m distinct rooms chosen
+// with a probability proportional to their degree.
+func barabasiAlbert(o Options, r *rand.Rand) (*topology, error) {
+	if o.M < 1 || o.Rooms <= o.M {
+		return nil, fmt.Errorf("ba needs m >= 1 and more than m rooms (m=%d, rooms=%d)", o.M, o.Rooms)
+	}
+
+	t := &topology{rooms: o.Rooms}
+	var ends []int // every room once per tunnel end, for degree-proportional picks
+	for a := 0; a <= o.M; a++ {
+		for b := a + 1; b <= o.M; b++ {
+			t.edges = append(t.edges, [2]int{a, b})
+			ends = append(ends, a, b)
+		}
+	}
+
+	for room := o.M + 1; room < o.Rooms; room++ {
+		targets := make(map[int]bool)
+		var picked []int
+		for len(picked) < o.M {
+			target := ends[r.Intn(len(ends))]
+			if !targets[target] {
+				targets[target] = true
+				picked = append(picked, target)
+			}
+		}
+		for _, target := range picked {
+			t.edges = append(t.edges, [2]int{room, target})
+			ends = append(ends, room, target)
+		}
+	}
+	t.useFarthest()
+	return t, nil
+}
+
+// wattsStrogatz builds a small-world graph: a ring where every room is
+// connected to its k nearest neighbors, with every tunnel rewired to a
+// random room with probability beta.
+func wattsStrogatz(o Options, r *rand.Rand) (*topology, error) {
+	if o.K < 2 || o.K%2 != 0 || o.Rooms <= o.K {
+		return nil, fmt.Errorf("ws needs an even k >= 2 and more than k rooms (k=%d, rooms=%d)", o.K, o.Rooms)
+	}
+	if o.Beta < 0 || o.Beta > 1 {
+		return nil, fmt.Errorf("ws needs beta between 0 and 1 (beta=%g)", o.Beta)
+	}
+
+	t := &topology{rooms: o.Rooms}
+	set := make(edgeSet)
+	for room := 0; room < o.Rooms; room++ {
+		for j := 1; j <= o.K/2; j++ {
+			t.edges = append(t.edges, [2]int{room, (room + j) % o.Rooms})
+			set.add(room, (room+j)%o.Rooms)
+		}
+	}
+
+	for i, e := range t.edges {
+		if r.Float64() >= o.Beta {
+			continue
+		}
+		// Give up on rooms that are already connected to almost everything
+		for tries := 0; tries < o.Rooms; tries++ {
+			target := r.Intn(o.Rooms)
+			if target == e[0] || set.has(e[0], target) {
+				continue
+			}
+			set.remove(e[0], e[1])
+			set.add(e[0], target)
+			t.edges[i] = [2]int{e[0], target}
+			break
+		}
+	}
+	t.useFarthest()
+	return t, nil
+}
+
+// disjointPaths builds exactly k vertex-disjoint paths from start (room 0)
+// to end (room 1). Distractor tunnels either lead to dead-end rooms or join
+// rooms at the same distance from start on two different paths: neither
+// creates a shorter route, and start keeps exactly k neighbors, so the
+// maximum number of disjoint paths and their lengths are known exactly.
+func disjointPaths(o Options, r *rand.Rand) (*topology, error) {
+	if o.DisjointPaths < 1 || len(o.PathLengths) == 0 {
+		return nil, fmt.Errorf("disjoint needs at least one path and one path length")
+	}
+	t := &topology{rooms: 2, start: 0, end: 1}
+
+	var paths [][]int // rooms of every path between start and end
+	direct := false
+	for i := 0; i < o.DisjointPaths; i++ {
+		length := o.PathLengths[min(i, len(o.PathLengths)-1)]
+		if length < 1 || length == 1 && direct {
+			return nil, fmt.Errorf("invalid path length %d (only one path can have length 1)", length)
+		}
+		direct = direct || length == 1
+
+		path := make([]int, length-1)
+		prev := t.start
+		for j := range path {
+			path[j] = t.rooms
+			t.rooms++
+			t.edges = append(t.edges, [2]int{prev, path[j]})
+			prev = path[j]
+		}
+		t.edges = append(t.edges, [2]int{prev, t.end})
+		paths = append(paths, path)
+	}
+
+	set := make(edgeSet)
+	for _, e := range t.edges {
+		set.add(e[0], e[1])
+	}
+	for added, tries := 0, 0; added < o.Distractors && tries < 100*o.Distractors; tries++ {
+		i, j := r.Intn(len(paths)), r.Intn(len(paths))
+		if len(paths[i]) == 0 {
+			continue
+		}
+		depth := r.Intn(len(paths[i]))
+		from := paths[i][depth]
+		if i == j || depth >= len(paths[j]) || r.Intn(2) == 0 {
+			// Dead end hanging off the path
+			t.edges = append(t.edges, [2]int{from, t.rooms})
+			t.rooms++
+			added++
+			continue
+		}
+		if to := paths[j][depth]; !set.has(from, to) {
+			set.add(from, to)
+			t.edges = append(t.edges, [2]int{from, to})
+			added++
+		}
+	}
+	return t, nil
+}
+
+// hub builds an adversarial map around a single hub room connected to every
+// other room. Start reaches the hub through m entry rooms and the hub
+// reaches end through m exit rooms; all remaining rooms are dead ends.
+func hub(o Options, r *rand.Rand) (*topology, error) {
+	if o.M < 1 || o.Rooms < 3+2*o.M {
+		return nil, fmt.Errorf("hub needs m >= 1 and at least 3+2m rooms (m=%d, rooms=%d)", o.M, o.Rooms)
+	}
+
+	const center = 2
+	t := &topology{rooms: o.Rooms, start: 0, end: 1}
+	spokes := r.Perm(o.Rooms - 3)
+	for i, spoke := range spokes {
+		room := spoke + 3
+		t.edges = append(t.edges, [2]int{center, room})
+		switch {
+		case i < o.M:
+			t.edges = append(t.edges, [2]int{t.start, room})
+		case i < 2*o.M:
+			t.edges = append(t.edges, [2]int{room, t.end})
+		}
+	}
+	return t, nil
+}
+
+// gnm builds a connected graph with exactly the requested number of
+// tunnels: a random spanning tree, where every room joins an earlier one,
+// plus tunnels between random pairs of rooms that are not connected yet.
+func gnm(o Options, r *rand.Rand) (*topology, error) {
+	most := o.Rooms * (o.Rooms - 1) / 2
+	if o.Rooms < 2 || o.Tunnels < o.Rooms-1 || o.Tunnels > most {
+		return nil, fmt.Errorf("gnm needs at least 2 rooms and between rooms-1 and rooms*(rooms-1)/2 tunnels (rooms=%d, tunnels=%d)", o.Rooms, o.Tunnels)
+	}
+
+	t := &topology{rooms: o.Rooms}
+	set := make(edgeSet)
+	for room := 1; room < o.Rooms; room++ {
+		parent := r.Intn(room)
+		t.edges = append(t.edges, [2]int{parent, room})
+		set.add(parent, room)
+	}
+	for len(t.edges) < o.Tunnels {
+		a, b := r.Intn(o.Rooms), r.Intn(o.Rooms)
+		if a == b || set.has(a, b) {
+			continue
+		}
+		t.edges = append(t.edges, [2]int{a, b})
+		set.add(a, b)
+	}
+	t.useFarthest()
+	return t, nil
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"unsafe"
+
+	"lem2/pkg/colony"
+)
+
+// ParseBytes parses a colony description held in memory. The lines and
+// room names of the result point into data instead of copying it, so data
+// must not be modified afterwards.
+func ParseBytes(data []byte, profile Profile) (*colony.Colony, error) {
+	c, err := ParseLines(splitLines(data), profile)
+	if err == nil && profile.KeepRaw {
+		c.Raw = data
+	}
+	return c, err
+}
+
+// splitLines splits data like bufio.ScanLines does, without copying
+func splitLines(data []byte) []string {
+	lines := make([]string, 0, bytes.Count(data, []byte{'\n'})+1)
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		next := end + 1
+		if end < 0 {
+			end, next = len(data), len(data)
+		}
+		line := data[:end]
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+		lines = append(lines, unsafe.String(unsafe.SliceData(line), len(line)))
+		data = data[next:]
+	}
+	return lines
+}
+
+// ParseInputMmap parses a file through a read-only memory mapping, which
+// avoids copying huge generated maps into memory. The mapping is never
+// released because the colony refers to it. Standard input ("-"), pipes
+// and other files that cannot be mapped are read as a stream instead.
+func ParseInputMmap(filename string, profile Profile) (*colony.Colony, error) {
+	if filename == "-" {
+		return ParseInput(filename, profile)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return ParseInput(filename, profile)
+	}
+
+	data, err := mapFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBytes(data, profile)
+}
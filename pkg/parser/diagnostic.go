@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Diagnostic describes a problem in a colony description. Lines and columns
+// start at 1, matching what editors expect.
+type Diagnostic struct {
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Message  string `json:"message"`
+	Reason   Reason `json:"reason,omitempty"`
+}
+
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", d.Line, d.Column, d.Severity, d.Message)
+}
+
+func errorAt(line, col int, reason Reason, message string) *Diagnostic {
+	return &Diagnostic{Line: line, Column: col, Severity: "error", Message: message, Reason: reason}
+}
+
+func warningAt(line, col int, message string) *Diagnostic {
+	return &Diagnostic{Line: line, Column: col, Severity: "warning", Message: message}
+}
+
+// column returns the 1-based column of token inside line
+func column(line, token string) int {
+	return strings.Index(line, token) + 1
+}
+
+// fieldColumn returns the 1-based column of the n-th whitespace separated field
+func fieldColumn(line string, n int) int {
+	inField := false
+	for i, r := range line {
+		if r == ' ' || r == '\t' {
+			inField = false
+			continue
+		}
+		if !inField {
+			if n == 0 {
+				return i + 1
+			}
+			n--
+			inField = true
+		}
+	}
+	return 1
+}
+
+// Diagnose parses lines and reports every problem found: all invalid
+// lines, or, for a colony that parses, warnings about things that are
+// unlikely to be what the author meant.
+func Diagnose(lines []string, profile Profile) []Diagnostic {
+	p := newParser(lines, profile)
+	p.all = true
+	if d := p.parse(); d != nil {
+		return p.errors
+	}
+
+	var diagnostics []Diagnostic
+	for _, w := range p.c.Validate().Warnings {
+		line := p.roomLine[w.Room]
+		diagnostics = append(diagnostics, *warningAt(line, column(lines[line-1], w.Room), w.Message))
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Line < diagnostics[j].Line
+	})
+	return diagnostics
+}
@@ -0,0 +1,135 @@
+package parser
+
+import "errors"
+
+// Sentinel errors of the parser, meant to be tested with errors.Is.
+// ErrNoPath is also what the solvers return when the end cannot be
+// reached, so a missing path matches it whichever finds out first.
+var (
+	ErrInvalidFormat = errors.New("ERROR: invalid data format")
+	ErrDuplicateRoom = errors.New("duplicate room")
+	ErrNoPath        = errors.New("no path between start and end")
+)
+
+// Reason classifies the problem behind an error diagnostic. Warnings have
+// no reason.
+type Reason int
+
+const (
+	NoReason Reason = iota
+	MissingAnts
+	BadAntCount
+	CommentNotAllowed
+	BadLine // neither a room nor a tunnel
+	LeadingL
+	BadCoordinate
+	DuplicateRoom
+	UnknownTunnelEndpoint
+	SelfLoopTunnel
+	DuplicateTunnel
+	BadCapacity
+	NoStart
+	NoEnd
+	NoPath
+	BadZone
+)
+
+var reasonNames = [...]string{
+	NoReason:              "",
+	MissingAnts:           "missing-ants",
+	BadAntCount:           "bad-ant-count",
+	CommentNotAllowed:     "comment-not-allowed",
+	BadLine:               "bad-line",
+	LeadingL:              "leading-l",
+	BadCoordinate:         "bad-coordinate",
+	DuplicateRoom:         "duplicate-room",
+	UnknownTunnelEndpoint: "unknown-tunnel-endpoint",
+	SelfLoopTunnel:        "self-loop-tunnel",
+	DuplicateTunnel:       "duplicate-tunnel",
+	BadCapacity:           "bad-capacity",
+	NoStart:               "no-start",
+	NoEnd:                 "no-end",
+	NoPath:                "no-path",
+	BadZone:               "bad-zone",
+}
+
+func (r Reason) String() string {
+	if r < 0 || int(r) >= len(reasonNames) {
+		return "unknown"
+	}
+	return reasonNames[r]
+}
+
+var reasonExplanations = [...]string{
+	NoReason:              "",
+	MissingAnts:           "The first line of a map must be the number of ants.",
+	BadAntCount:           "The first line must hold the number of ants, a whole number greater than zero.",
+	CommentNotAllowed:     "This profile does not accept comments; only ##start and ##end may start with #.",
+	BadLine:               "After the number of ants, every line is a room \"name x y\", a tunnel \"a-b\", ##start, ##end or a comment.",
+	LeadingL:              "Room names cannot start with L, since moves are written L<ant>-<room>.",
+	BadCoordinate:         "Room coordinates must be whole numbers.",
+	DuplicateRoom:         "Every room may be defined only once; rename one of the rooms.",
+	UnknownTunnelEndpoint: "A tunnel may only join rooms defined above it.",
+	SelfLoopTunnel:        "A tunnel must join two different rooms.",
+	DuplicateTunnel:       "Two rooms may be joined by a single tunnel.",
+	BadCapacity:           "A capacity must be a whole number of at least 1.",
+	NoStart:               "One room must be marked as the start by a ##start line just above it.",
+	NoEnd:                 "One room must be marked as the end by a ##end line just above it.",
+	NoPath:                "The ants cannot reach the end room from the start room through the tunnels.",
+	BadZone:               "A zone is \"##zone name room...\" or \"##zone name:capacity room...\", with a new name and rooms that are in no other zone.",
+}
+
+// Explain returns a sentence telling what the rule behind r is, for
+// people reading an error rather than programs
+func (r Reason) Explain() string {
+	if r < 0 || int(r) >= len(reasonExplanations) {
+		return ""
+	}
+	return reasonExplanations[r]
+}
+
+func (r Reason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// ParseError is returned for an invalid colony. Its message is the one of
+// ErrInvalidFormat, as the project requires, so the audit output stays a
+// single line, or "ERROR: " followed by the one of ErrNoPath when the end
+// cannot be reached; the diagnostic tells what is wrong and where, and
+// Text holds the offending line when the problem is on one. errors.Is
+// matches ErrInvalidFormat as well as ErrDuplicateRoom for duplicate rooms
+// and ErrNoPath for NoPath.
+type ParseError struct {
+	Diagnostic
+	Text string
+}
+
+func (e *ParseError) Error() string {
+	if e.Reason == NoPath {
+		return "ERROR: " + ErrNoPath.Error()
+	}
+	return ErrInvalidFormat.Error()
+}
+
+func (e *ParseError) Unwrap() []error {
+	switch e.Reason {
+	case DuplicateRoom:
+		return []error{ErrInvalidFormat, ErrDuplicateRoom}
+	case NoPath:
+		return []error{ErrInvalidFormat, ErrNoPath}
+	}
+	return []error{ErrInvalidFormat}
+}
+
+// newParseError wraps the diagnostic d of a parse of lines
+func newParseError(d *Diagnostic, lines []string) *ParseError {
+	e := &ParseError{Diagnostic: *d}
+	switch d.Reason {
+	case MissingAnts, NoStart, NoEnd:
+	default:
+		if d.Line >= 1 && d.Line <= len(lines) {
+			e.Text = lines[d.Line-1]
+		}
+	}
+	return e
+}
@@ -0,0 +1,352 @@
+package parser_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"lem2/pkg/parser"
+)
+
+func ExampleParseLines() {
+	c, err := parser.ParseLines([]string{
+		"3",
+		"##start",
+		"start 0 0",
+		"middle 1 0",
+		"##end",
+		"end 2 0",
+		"start-middle",
+		"middle-end",
+	}, parser.Strict01Edu)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(c.Ants, "ants from", c.Start, "to", c.End)
+	fmt.Println(len(c.Rooms), "rooms,", len(c.Tunnels), "tunnels")
+	// Output:
+	// 3 ants from start to end
+	// 3 rooms, 2 tunnels
+}
+
+func ExampleParseReader() {
+	input := `2
+##start
+home 0 0
+##end
+away 1 0
+home-away
+`
+	c, err := parser.ParseReader(strings.NewReader(input), parser.Strict01Edu)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(c.Ants, "ants from", c.Start, "to", c.End)
+	// Output:
+	// 2 ants from home to away
+}
+
+func ExampleParseError() {
+	_, err := parser.ParseLines([]string{
+		"1",
+		"##start",
+		"a 0 0",
+		"a 1 0",
+		"##end",
+		"b 2 0",
+		"a-b",
+	}, parser.Strict01Edu)
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, parser.ErrDuplicateRoom))
+
+	var parseErr *parser.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println(parseErr.Diagnostic.String())
+		fmt.Printf("%s in %q\n", parseErr.Reason, parseErr.Text)
+	}
+	// Output:
+	// ERROR: invalid data format
+	// true
+	// 4:1: error: duplicate room a
+	// duplicate-room in "a 1 0"
+}
+
+func ExampleDiagnose() {
+	diagnostics := parser.Diagnose([]string{
+		"3",
+		"##start",
+		"start 0 0",
+		"##end",
+		"end 1 0",
+	}, parser.Strict01Edu)
+	for _, d := range diagnostics {
+		fmt.Println(d.String())
+	}
+	// Output:
+	// 3:1: warning: room start has no tunnels
+	// 5:1: warning: room end has no tunnels
+	// 5:1: warning: start and end are not connected
+}
+
+func ExampleDiagnose_multipleTerminals() {
+	diagnostics := parser.Diagnose([]string{
+		"2",
+		"##start",
+		"s1 0 0",
+		"##start",
+		"s2 0 1",
+		"##end",
+		"e1 1 0",
+		"##end",
+		"e2 1 1",
+		"s1-e2",
+		"s2-e1",
+	}, parser.Lenient)
+	fmt.Println(len(diagnostics))
+	// Output:
+	// 0
+}
+
+func ExampleProfile() {
+	lines := []string{
+		"1",
+		"##start",
+		"a 0 0",
+		"##end",
+		"b 1 0",
+		"a-b",
+		"b-a",
+	}
+	_, err := parser.ParseLines(lines, parser.Strict01Edu)
+	var parseErr *parser.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println("strict:", parseErr.Reason, "on line", parseErr.Line)
+	}
+
+	c, err := parser.ParseLines(lines, parser.Lenient)
+	if err == nil {
+		fmt.Println("lenient:", len(c.Tunnels), "tunnel")
+	}
+	// Output:
+	// strict: duplicate-tunnel on line 7
+	// lenient: 1 tunnel
+}
+
+func ExampleProfile_tunnelCapacity() {
+	lines := []string{
+		"4",
+		"##start",
+		"a 0 0",
+		"##end",
+		"b 1 0",
+		"a-b 2",
+	}
+	_, err := parser.ParseLines(lines, parser.Strict01Edu)
+	var parseErr *parser.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println("strict:", parseErr.Reason, "on line", parseErr.Line)
+	}
+
+	c, err := parser.ParseLines(lines, parser.Lenient)
+	if err == nil {
+		fmt.Println("lenient: capacity", c.TunnelCapacity("b", "a"))
+	}
+
+	lines[5] = "a-b 0"
+	if _, err := parser.ParseLines(lines, parser.Lenient); errors.As(err, &parseErr) {
+		fmt.Println("lenient:", parseErr.Reason, "on line", parseErr.Line, "column", parseErr.Column)
+	}
+	// Output:
+	// strict: bad-line on line 6
+	// lenient: capacity 2
+	// lenient: bad-capacity on line 6 column 5
+}
+
+func ExampleProfile_requirePath() {
+	lines := []string{
+		"2",
+		"##start",
+		"a 0 0",
+		"b 1 0",
+		"##end",
+		"c 2 0",
+		"a-b",
+	}
+	profile := parser.Strict01Edu
+	profile.RequirePath = true
+	_, err := parser.ParseLines(lines, profile)
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, parser.ErrNoPath), errors.Is(err, parser.ErrInvalidFormat))
+
+	var parseErr *parser.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println(parseErr.Reason, "on line", parseErr.Line)
+	}
+	// Output:
+	// ERROR: no path between start and end
+	// true true
+	// no-path on line 6
+}
+
+func ExampleProfile_roomCapacity() {
+	lines := []string{
+		"4",
+		"##start",
+		"a 0 0",
+		"hall 1 0 3",
+		"##end",
+		"b 2 0",
+		"a-hall",
+		"hall-b",
+	}
+	_, err := parser.ParseLines(lines, parser.Strict01Edu)
+	var parseErr *parser.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println("strict:", parseErr.Reason, "on line", parseErr.Line)
+	}
+
+	c, err := parser.ParseLines(lines, parser.Lenient)
+	if err == nil {
+		fmt.Println("lenient: capacity", c.RoomCapacity("hall"), "and", c.RoomCapacity("a"))
+	}
+
+	lines[3] = "hall 1 0 many"
+	if _, err := parser.ParseLines(lines, parser.Lenient); errors.As(err, &parseErr) {
+		fmt.Println("lenient:", parseErr.Reason, "on line", parseErr.Line, "column", parseErr.Column)
+	}
+	// Output:
+	// strict: bad-line on line 4
+	// lenient: capacity 3 and 1
+	// lenient: bad-capacity on line 4 column 10
+}
+
+func ExampleProfile_multipleTerminals() {
+	lines := []string{
+		"2",
+		"##start",
+		"a 0 0",
+		"##start",
+		"b 0 1",
+		"##end",
+		"c 1 0",
+		"a-c",
+		"b-c",
+	}
+	c, err := parser.ParseLines(lines, parser.Strict01Edu)
+	if err == nil {
+		fmt.Println("strict:", c.StartRooms())
+	}
+
+	c, err = parser.ParseLines(lines, parser.Lenient)
+	if err == nil {
+		fmt.Println("lenient:", c.StartRooms(), c.EndRooms())
+	}
+	// Output:
+	// strict: [b]
+	// lenient: [a b] [c]
+}
+
+func ExampleProfile_directedTunnels() {
+	lines := []string{
+		"1",
+		"##start",
+		"a 0 0",
+		"##end",
+		"b 1 0",
+		"a->b",
+	}
+	_, err := parser.ParseLines(lines, parser.Strict01Edu)
+	var parseErr *parser.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println("strict:", parseErr.Reason, "on line", parseErr.Line)
+	}
+
+	c, err := parser.ParseLines(lines, parser.Lenient)
+	if err == nil {
+		fmt.Println("lenient:", c.Leads("a", "b"), c.Leads("b", "a"))
+	}
+	// Output:
+	// strict: unknown-tunnel-endpoint on line 6
+	// lenient: true false
+}
+
+// A tunnel given in both directions is open both ways, whether or not the
+// profile drops duplicate tunnels
+func ExampleProfile_twoWayTunnel() {
+	lines := []string{
+		"1",
+		"##start",
+		"a 0 0",
+		"##end",
+		"b 1 0",
+		"a->b",
+		"b->a",
+	}
+	directed := parser.Strict01Edu
+	directed.AllowDirectedTunnels = true
+	for _, profile := range []parser.Profile{directed, parser.Lenient} {
+		c, err := parser.ParseLines(lines, profile)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(len(c.Tunnels), "tunnels:", c.Leads("a", "b"), c.Leads("b", "a"))
+	}
+	formatted, _ := parser.Format(lines)
+	fmt.Println(strings.Join(formatted[5:], " "))
+	// Output:
+	// 2 tunnels: true true
+	// 2 tunnels: true true
+	// a->b b->a
+}
+
+func ExampleProfile_zones() {
+	lines := []string{
+		"3",
+		"##zone west a b",
+		"##start",
+		"s 0 0",
+		"a 1 0",
+		"b 1 1",
+		"##end",
+		"e 2 0",
+		"s-a",
+		"a-e",
+		"s-b",
+		"b-e",
+	}
+	c, err := parser.ParseLines(lines, parser.Strict01Edu)
+	if err == nil {
+		fmt.Println("strict:", len(c.Zones), "zones")
+	}
+
+	c, err = parser.ParseLines(lines, parser.Lenient)
+	if err == nil {
+		fmt.Println("lenient:", c.Zones, c.ZoneOf("b"))
+	}
+
+	lines[1] = "##zone west a x"
+	_, err = parser.ParseLines(lines, parser.Lenient)
+	var parseErr *parser.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println("lenient:", parseErr.Reason, "on line", parseErr.Line, "column", parseErr.Column)
+	}
+	// Output:
+	// strict: 0 zones
+	// lenient: [{west [a b] 0}] west
+	// lenient: bad-zone on line 2 column 15
+}
+
+func ExampleReason_Explain() {
+	_, err := parser.ParseLines([]string{"0"}, parser.Strict01Edu)
+	var parseErr *parser.ParseError
+	if errors.As(err, &parseErr) {
+		fmt.Println(parseErr.Diagnostic.String())
+		fmt.Println(parseErr.Reason.Explain())
+	}
+	// Output:
+	// 1:1: error: invalid number of ants
+	// The first line must hold the number of ants, a whole number greater than zero.
+}
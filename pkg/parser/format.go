@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format rewrites a colony description in canonical form: the number of
+// ants, the ##start room, the ##end room, the remaining rooms sorted by name
+// and the tunnels sorted, with whitespace normalized and capacities such
+// as "a-b 3" or "name x y 5" and directed tunnels such as "a->b" kept.
+// Comments are kept above the room or tunnel they precede; comments after
+// the last room or tunnel stay at the end.
+func Format(lines []string) ([]string, error) {
+	profile := Strict01Edu
+	profile.AllowTunnelCapacity = true
+	profile.AllowRoomCapacity = true
+	profile.AllowDirectedTunnels = true
+	c, err := ParseLines(lines, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	comments := make(map[string][]string) // room name or tunnel -> comments above it
+
+	// The first line always holds the number of ants
+	for _, raw := range lines[1:] {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "" || line == "##start" || line == "##end":
+			continue
+		case strings.HasPrefix(line, "#"):
+			pending = append(pending, line)
+			continue
+		}
+
+		fields := strings.Fields(line)
+		key := fields[0]
+		if from, to, ok := strings.Cut(key, "-"); ok && len(fields) <= 2 {
+			key = tunnelKey(from, strings.TrimPrefix(to, ">"))
+		}
+		comments[key] = append(comments[key], pending...)
+		pending = nil
+	}
+
+	out := []string{strconv.Itoa(c.Ants)}
+
+	writeRoom := func(name string, commands ...string) {
+		out = append(out, comments[name]...)
+		out = append(out, commands...)
+		room := c.Rooms[name]
+		line := fmt.Sprintf("%s %d %d", room.Name, room.X, room.Y)
+		if n := c.RoomCapacity(name); n != 1 {
+			line += " " + strconv.Itoa(n)
+		}
+		out = append(out, line)
+	}
+	if c.End == c.Start {
+		// A room that is both start and end carries both commands
+		writeRoom(c.Start, "##start", "##end")
+	} else {
+		writeRoom(c.Start, "##start")
+		writeRoom(c.End, "##end")
+	}
+
+	var names []string
+	for name := range c.Rooms {
+		if name != c.Start && name != c.End {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeRoom(name)
+	}
+
+	// Tunnels are sorted by their rooms in sorted order, so "a->b" and
+	// "b->a" end up next to each other and share the comments above them
+	type tunnelLine struct{ key, line string }
+	var tunnels []tunnelLine
+	for _, tunnel := range c.Tunnels {
+		key := tunnelKey(tunnel.From, tunnel.To)
+		line := key
+		if tunnel.Directed {
+			line = tunnel.From + "->" + tunnel.To
+		}
+		if n := c.TunnelCapacity(tunnel.From, tunnel.To); n != 1 {
+			line += " " + strconv.Itoa(n)
+		}
+		tunnels = append(tunnels, tunnelLine{key, line})
+	}
+	sort.Slice(tunnels, func(i, j int) bool {
+		if tunnels[i].key != tunnels[j].key {
+			return tunnels[i].key < tunnels[j].key
+		}
+		return tunnels[i].line < tunnels[j].line
+	})
+	for i, tunnel := range tunnels {
+		if i == 0 || tunnel.key != tunnels[i-1].key {
+			out = append(out, comments[tunnel.key]...)
+		}
+		out = append(out, tunnel.line)
+	}
+	return append(out, pending...), nil
+}
+
+// tunnelKey writes a tunnel with its rooms in sorted order
+func tunnelKey(from, to string) string {
+	if to < from {
+		from, to = to, from
+	}
+	return from + "-" + to
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package parser
+
+import "os"
+
+// Without mmap support the file is simply read into memory
+func mapFile(filename string) ([]byte, error) {
+	return os.ReadFile(filename)
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"lem2/pkg/colony"
+)
+
+// parallelTunnelLines is the number of remaining lines from which the
+// tunnel section is split into chunks parsed concurrently
+const parallelTunnelLines = 100000
+
+// tunnelChunk is the result of parsing one chunk of the tunnel section
+type tunnelChunk struct {
+	tunnels []colony.Tunnel
+	lineNos []int // line of every tunnel, for duplicates found when merging
+	err     *Diagnostic
+	mixed   bool // the chunk holds something other than tunnels and comments
+}
+
+// parseTunnelsParallel parses lines[from:] as the tunnel section with one
+// worker per CPU, merging the chunks in input order. It reports false when
+// the section also contains rooms or commands, ##zone included, leaving
+// the colony untouched so the caller can fall back to parsing it
+// sequentially.
+func (p *parser) parseTunnelsParallel(from int) (bool, *Diagnostic) {
+	lines := p.lines[from:]
+	workers := runtime.GOMAXPROCS(0)
+	size := (len(lines) + workers - 1) / workers
+	chunks := make([]tunnelChunk, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		lo, hi := w*size, (w+1)*size
+		if lo >= len(lines) {
+			break
+		}
+		if hi > len(lines) {
+			hi = len(lines)
+		}
+
+		wg.Add(1)
+		go func(chunk *tunnelChunk, lo, hi int) {
+			defer wg.Done()
+			chunk.tunnels = make([]colony.Tunnel, 0, hi-lo)
+			chunk.lineNos = make([]int, 0, hi-lo)
+			for i := lo; i < hi; i++ {
+				line := strings.TrimSpace(lines[i])
+				zone := p.profile.AllowZones && (line == "##zone" || strings.HasPrefix(line, "##zone "))
+				if line == "" || strings.HasPrefix(line, "#") && line != "##start" && line != "##end" && !zone && p.profile.AllowComments {
+					continue
+				}
+				// Rejected comments are reported by the sequential parse
+				if !isTunnel(line) || strings.HasPrefix(line, "#") {
+					chunk.mixed = true
+					return
+				}
+				tunnel, d := p.tunnelAt(lines[i], from+i+1)
+				if d != nil && d.Reason == UnknownTunnelEndpoint && p.profile.AllowForwardTunnels {
+					// The room may be defined further down, which only the
+					// sequential parse can tell
+					chunk.mixed = true
+					return
+				}
+				if d != nil {
+					chunk.err = d
+					return
+				}
+				chunk.tunnels = append(chunk.tunnels, tunnel)
+				chunk.lineNos = append(chunk.lineNos, from+i+1)
+			}
+		}(&chunks[w], lo, hi)
+	}
+	wg.Wait()
+
+	// Any chunk with rooms or commands sends the whole section to the
+	// sequential parse, even when an earlier chunk failed: the failure may
+	// depend on the lines that parse understands
+	for _, chunk := range chunks {
+		if chunk.mixed {
+			return false, nil
+		}
+	}
+	total := 0
+	for _, chunk := range chunks {
+		if chunk.err != nil {
+			return false, chunk.err
+		}
+		total += len(chunk.tunnels)
+	}
+
+	p.c.Tunnels = slices.Grow(p.c.Tunnels, total)
+	for _, chunk := range chunks {
+		for i, tunnel := range chunk.tunnels {
+			lineNo := chunk.lineNos[i]
+			if d := p.addTunnel(tunnel, p.lines[lineNo-1], lineNo); d != nil {
+				return false, d
+			}
+		}
+	}
+	return true, nil
+}
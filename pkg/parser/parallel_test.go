@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"lem2/pkg/colony"
+)
+
+// chainMap returns a map whose tunnel section is long enough to be parsed
+// in parallel: a chain of rooms from start to end, with the tunnel lines
+// first added before the chain's tunnels and the lines last after them
+func chainMap(first, last []string) []string {
+	lines := []string{"1", "##start", "s 0 0", "##end", "e 0 1"}
+	for i := 0; i < parallelTunnelLines; i++ {
+		lines = append(lines, fmt.Sprintf("r%d %d 2", i, i))
+	}
+	lines = append(lines, first...)
+	lines = append(lines, "s-r0")
+	for i := 1; i < parallelTunnelLines; i++ {
+		lines = append(lines, fmt.Sprintf("r%d-r%d", i-1, i))
+	}
+	lines = append(lines, fmt.Sprintf("r%d-e", parallelTunnelLines-1))
+	return append(lines, last...)
+}
+
+// parseWithProcs parses lines with the given GOMAXPROCS, so the tunnel
+// section is parsed sequentially with 1 and in parallel above
+func parseWithProcs(t *testing.T, procs int, lines []string, profile Profile) (*colony.Colony, error) {
+	t.Helper()
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+	return ParseLines(lines, profile)
+}
+
+// TestParallelForwardTunnels parses a huge map with a tunnel to a room
+// defined after the tunnels, which the lenient profile allows whether or
+// not the tunnel section is parsed in parallel
+func TestParallelForwardTunnels(t *testing.T) {
+	lines := chainMap([]string{"late-r5"}, []string{"late 9 9"})
+	for _, procs := range []int{1, 4} {
+		if _, err := parseWithProcs(t, procs, lines, Lenient); err != nil {
+			t.Errorf("GOMAXPROCS=%d: %v", procs, err)
+		}
+	}
+}
+
+// TestParallelZones parses a huge map with a zone declared after the
+// tunnels, which must not be dropped as a comment when the tunnel section
+// is parsed in parallel
+func TestParallelZones(t *testing.T) {
+	lines := chainMap(nil, []string{"##zone hall r1 r2"})
+	for _, procs := range []int{1, 4} {
+		c, err := parseWithProcs(t, procs, lines, Lenient)
+		if err != nil {
+			t.Errorf("GOMAXPROCS=%d: %v", procs, err)
+			continue
+		}
+		if len(c.Zones) != 1 || c.Zones[0].Name != "hall" || len(c.Zones[0].Rooms) != 2 {
+			t.Errorf("GOMAXPROCS=%d: got zones %v, want hall with r1 and r2", procs, c.Zones)
+		}
+	}
+}
+
+// TestParallelZoneCapacity checks that the capacity of a zone declared
+// after the tunnels of a huge map survives the parallel tunnel parse
+func TestParallelZoneCapacity(t *testing.T) {
+	lines := chainMap(nil, []string{"##zone hall:3 r1 r2"})
+	for _, procs := range []int{1, 4} {
+		c, err := parseWithProcs(t, procs, lines, Lenient)
+		if err != nil {
+			t.Errorf("GOMAXPROCS=%d: %v", procs, err)
+			continue
+		}
+		if n := c.ZoneCapacity("hall"); n != 3 {
+			t.Errorf("GOMAXPROCS=%d: got capacity %d for hall, want 3", procs, n)
+		}
+	}
+}
+
+// BenchmarkTunnelSection parses a map whose tunnel section is split into
+// chunks, sequentially with GOMAXPROCS=1 and in 4 chunks above
+func BenchmarkTunnelSection(b *testing.B) {
+	lines := chainMap(nil, nil)
+	for _, procs := range []int{1, 4} {
+		b.Run(fmt.Sprintf("procs=%d", procs), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseLines(lines, Strict01Edu); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package parser
+
+import (
+	"cmp"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"lem2/pkg/colony"
+)
+
+// ParseInput reads a colony description from a file, or from standard
+// input when filename is "-"
+func ParseInput(filename string, profile Profile) (*colony.Colony, error) {
+	if filename == "-" {
+		return ParseReader(os.Stdin, profile)
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseReader(file, profile)
+}
+
+// ParseReader reads a colony description from r
+func ParseReader(r io.Reader, profile Profile) (*colony.Colony, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBytes(data, profile)
+}
+
+// ParseLines parses the lines of a colony description: the number of ants,
+// the rooms (with ##start and ##end marking the next room) and the tunnels.
+func ParseLines(lines []string, profile Profile) (*colony.Colony, error) {
+	p := newParser(lines, profile)
+	if d := p.parse(); d != nil {
+		return nil, newParseError(d, lines)
+	}
+	return p.c, nil
+}
+
+// parser keeps the state of a single parse, including where every room was
+// defined so diagnostics can point back at the input
+type parser struct {
+	c        *colony.Colony
+	lines    []string
+	profile  Profile
+	roomLine map[string]int
+	tunnels  map[[2]string]bool // every direction of every tunnel added, from first
+
+	all    bool         // keep going after an error, collecting every problem
+	errors []Diagnostic // problems collected when all is set
+}
+
+func newParser(lines []string, profile Profile) *parser {
+	c := colony.New()
+	c.Input = lines
+	return &parser{c: c, lines: lines, profile: profile, roomLine: make(map[string]int), tunnels: make(map[[2]string]bool)}
+}
+
+// parse fills in the colony and returns the first problem found. When
+// p.all is set, invalid lines are skipped and every problem is collected
+// in p.errors; the first one is still returned.
+func (p *parser) parse() *Diagnostic {
+	if len(p.lines) == 0 {
+		return errorAt(1, 1, MissingAnts, "missing number of ants")
+	}
+	ants, err := strconv.Atoi(strings.TrimSpace(p.lines[0]))
+	if err != nil || ants <= 0 {
+		d := errorAt(1, column(p.lines[0], strings.TrimSpace(p.lines[0])), BadAntCount, "invalid number of ants")
+		if !p.collect(d) {
+			return d
+		}
+	}
+	p.c.Ants = ants
+
+	// Set by ##start and ##end for the next room; both may be pending when
+	// a single room is the start and the end
+	nextStart, nextEnd := false, false
+	var forward []int // tunnel lines naming rooms not defined yet
+	var zones []int   // ##zone lines, read once every room is known
+	tunnelsSeen := false
+	for i := 1; i < len(p.lines); i++ {
+		raw, lineNo := p.lines[i], i+1
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "":
+			continue
+		case line == "##start":
+			nextStart = true
+			continue
+		case line == "##end":
+			nextEnd = true
+			continue
+		case p.profile.AllowZones && (line == "##zone" || strings.HasPrefix(line, "##zone ")):
+			zones = append(zones, i)
+			continue
+		case strings.HasPrefix(line, "#"):
+			// Comments and unknown commands are ignored when allowed
+			if !p.profile.AllowComments {
+				if d := errorAt(lineNo, column(raw, line), CommentNotAllowed, "comments are not allowed"); !p.collect(d) {
+					return d
+				}
+			}
+			continue
+		}
+
+		if isTunnel(line) || p.isCapacityTunnel(line) {
+			// The tunnel section of huge maps is parsed in parallel
+			if !tunnelsSeen && !p.all && len(p.lines)-i >= parallelTunnelLines && runtime.GOMAXPROCS(0) > 1 {
+				if done, d := p.parseTunnelsParallel(i); d != nil || done {
+					if d != nil {
+						return d
+					}
+					break
+				}
+			}
+			tunnelsSeen = true
+			d := p.parseTunnel(raw, lineNo)
+			if d != nil && d.Reason == UnknownTunnelEndpoint && p.profile.AllowForwardTunnels {
+				forward = append(forward, i)
+				continue
+			}
+			if d != nil && !p.collect(d) {
+				return d
+			}
+			continue
+		}
+
+		room, d := p.parseRoom(raw, lineNo)
+		if d != nil {
+			if !p.collect(d) {
+				return d
+			}
+			nextStart, nextEnd = false, false
+			continue
+		}
+		if nextStart {
+			p.terminal(&p.c.Start, &p.c.Starts, room.Name)
+		}
+		if nextEnd {
+			p.terminal(&p.c.End, &p.c.Ends, room.Name)
+		}
+		nextStart, nextEnd = false, false
+	}
+	for _, i := range forward {
+		if d := p.parseTunnel(p.lines[i], i+1); d != nil && !p.collect(d) {
+			return d
+		}
+	}
+	for _, i := range zones {
+		if d := p.parseZone(p.lines[i], i+1); d != nil && !p.collect(d) {
+			return d
+		}
+	}
+
+	if p.c.Start == "" {
+		if d := errorAt(len(p.lines), 1, NoStart, "no ##start room"); !p.collect(d) {
+			return d
+		}
+	}
+	if p.c.End == "" {
+		if d := errorAt(len(p.lines), 1, NoEnd, "no ##end room"); !p.collect(d) {
+			return d
+		}
+	}
+	// A breadth-first search from the start, pointing at the end room
+	if p.profile.RequirePath && len(p.errors) == 0 && !p.c.Connected() {
+		line := p.roomLine[p.c.End]
+		if d := errorAt(line, column(p.lines[line-1], p.c.End), NoPath, "no path between start and end"); !p.collect(d) {
+			return d
+		}
+	}
+	if len(p.errors) > 0 {
+		return &p.errors[0]
+	}
+	return nil
+}
+
+// terminal records a room marked ##start or ##end in first and all. A
+// repeated mark adds one more room when the profile allows several;
+// otherwise the last room marked wins.
+func (p *parser) terminal(first *string, all *[]string, name string) {
+	if *first == "" || !p.profile.AllowMultipleTerminals {
+		*first = name
+		return
+	}
+	if *all == nil {
+		*all = []string{*first}
+	}
+	*all = append(*all, name)
+}
+
+// collect records d when collecting every error and reports whether
+// parsing should go on
+func (p *parser) collect(d *Diagnostic) bool {
+	if !p.all {
+		return false
+	}
+	p.errors = append(p.errors, *d)
+	return true
+}
+
+func (p *parser) parseRoom(raw string, lineNo int) (*colony.Room, *Diagnostic) {
+	fields := strings.Fields(raw)
+	if len(fields) == 1 && !p.profile.RequireCoordinates {
+		fields = append(fields, "0", "0")
+	}
+	if len(fields) != 3 && (len(fields) != 4 || !p.profile.AllowRoomCapacity) {
+		return nil, errorAt(lineNo, column(raw, strings.TrimSpace(raw)), BadLine, "expected a room \"name x y\" or a tunnel \"a-b\"")
+	}
+	if strings.HasPrefix(fields[0], "L") && !p.profile.AllowLeadingL {
+		return nil, errorAt(lineNo, column(raw, fields[0]), LeadingL, "room name cannot start with L")
+	}
+	x, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, errorAt(lineNo, fieldColumn(raw, 1), BadCoordinate, "invalid x coordinate "+fields[1])
+	}
+	y, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, errorAt(lineNo, fieldColumn(raw, 2), BadCoordinate, "invalid y coordinate "+fields[2])
+	}
+	capacity := 0
+	if len(fields) == 4 {
+		n, err := strconv.Atoi(fields[3])
+		if err != nil || n < 1 {
+			return nil, errorAt(lineNo, fieldColumn(raw, 3), BadCapacity, "invalid room capacity "+fields[3])
+		}
+		if n > 1 {
+			capacity = n
+		}
+	}
+	if _, exists := p.c.Rooms[fields[0]]; exists {
+		return nil, errorAt(lineNo, column(raw, fields[0]), DuplicateRoom, "duplicate room "+fields[0])
+	}
+
+	// The room map doubles as the intern table for room names: the name is
+	// copied once here and every tunnel refers to this copy
+	room := &colony.Room{Name: strings.Clone(fields[0]), X: x, Y: y, Capacity: capacity}
+	p.c.Rooms[room.Name] = room
+	p.roomLine[room.Name] = lineNo
+	return room, nil
+}
+
+// parseZone reads "##zone name room...", which groups rooms defined
+// anywhere in the map. "name:10" gives the zone a capacity of 10 ants.
+func (p *parser) parseZone(raw string, lineNo int) *Diagnostic {
+	fields := strings.Fields(raw)
+	if len(fields) < 3 {
+		return errorAt(lineNo, column(raw, "##zone"), BadZone, "expected a zone \"##zone name room...\"")
+	}
+	zone := colony.Zone{Name: fields[1]}
+	if name, capacity, ok := strings.Cut(fields[1], ":"); ok {
+		n, err := strconv.Atoi(capacity)
+		if err != nil || n < 1 || name == "" {
+			return errorAt(lineNo, fieldColumn(raw, 1), BadCapacity, "invalid zone capacity "+fields[1])
+		}
+		zone.Name, zone.Capacity = name, n
+	}
+	for _, other := range p.c.Zones {
+		if other.Name == zone.Name {
+			return errorAt(lineNo, fieldColumn(raw, 1), BadZone, "duplicate zone "+zone.Name)
+		}
+	}
+	for n, room := range fields[2:] {
+		if p.c.Rooms[room] == nil {
+			return errorAt(lineNo, fieldColumn(raw, n+2), BadZone, "zone "+zone.Name+" names unknown room "+room)
+		}
+		if other := p.c.ZoneOf(room); other != "" || slices.Contains(zone.Rooms, room) {
+			return errorAt(lineNo, fieldColumn(raw, n+2), BadZone, "room "+room+" is already in zone "+cmp.Or(other, zone.Name))
+		}
+		zone.Rooms = append(zone.Rooms, p.c.Rooms[room].Name)
+	}
+	p.c.Zones = append(p.c.Zones, zone)
+	return nil
+}
+
+func isTunnel(line string) bool {
+	return strings.Contains(line, "-") && !strings.Contains(line, " ")
+}
+
+// isCapacityTunnel reports whether line is a tunnel followed by its
+// capacity, "a-b 3", and the profile allows capacities
+func (p *parser) isCapacityTunnel(line string) bool {
+	fields := strings.Fields(line)
+	return p.profile.AllowTunnelCapacity && len(fields) == 2 && isTunnel(fields[0])
+}
+
+func (p *parser) parseTunnel(raw string, lineNo int) *Diagnostic {
+	tunnel, d := p.tunnelAt(raw, lineNo)
+	if d != nil {
+		return d
+	}
+	capacity := 1
+	if fields := strings.Fields(raw); len(fields) == 2 {
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 1 {
+			return errorAt(lineNo, fieldColumn(raw, 1), BadCapacity, "invalid tunnel capacity "+fields[1])
+		}
+		capacity = n
+	}
+	if d := p.addTunnel(tunnel, raw, lineNo); d != nil {
+		return d
+	}
+	if capacity != 1 {
+		p.c.SetTunnelCapacity(tunnel.From, tunnel.To, capacity)
+	}
+	return nil
+}
+
+// addTunnel adds a parsed tunnel to the colony unless a direction it opens
+// was given before. "a->b" and "b->a" are not duplicates: together they
+// open the tunnel both ways, like "a-b".
+func (p *parser) addTunnel(tunnel colony.Tunnel, raw string, lineNo int) *Diagnostic {
+	keys := [][2]string{{tunnel.From, tunnel.To}}
+	if !tunnel.Directed {
+		keys = append(keys, [2]string{tunnel.To, tunnel.From})
+	}
+	for _, key := range keys {
+		if !p.tunnels[key] {
+			continue
+		}
+		if p.profile.DropDuplicateTunnels {
+			return nil
+		}
+		return errorAt(lineNo, column(raw, strings.TrimSpace(raw)), DuplicateTunnel, "duplicate tunnel "+tunnel.From+"-"+tunnel.To)
+	}
+	for _, key := range keys {
+		p.tunnels[key] = true
+	}
+	p.c.AddTunnel(tunnel)
+	return nil
+}
+
+// tunnelAt parses a tunnel line without modifying the colony, so it can be
+// called from several goroutines once all rooms are known
+func (p *parser) tunnelAt(raw string, lineNo int) (colony.Tunnel, *Diagnostic) {
+	line := strings.TrimSpace(raw)
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		line = line[:i] // the capacity is parsed by parseTunnel
+	}
+	separator := "-"
+	if p.profile.AllowDirectedTunnels && strings.Contains(line, "->") {
+		separator = "->"
+	}
+	from, to, _ := strings.Cut(line, separator)
+	fromRoom, toRoom := p.c.Rooms[from], p.c.Rooms[to]
+	if fromRoom == nil {
+		return colony.Tunnel{}, errorAt(lineNo, column(raw, line), UnknownTunnelEndpoint, "unknown room "+from)
+	}
+	if toRoom == nil {
+		return colony.Tunnel{}, errorAt(lineNo, column(raw, line)+len(from)+len(separator), UnknownTunnelEndpoint, "unknown room "+to)
+	}
+	if fromRoom == toRoom {
+		return colony.Tunnel{}, errorAt(lineNo, column(raw, line), SelfLoopTunnel, "tunnel from "+from+" to itself")
+	}
+	// Use the interned names rather than slices of this line, so every
+	// occurrence of a room shares one string and comparisons between equal
+	// names hit the pointer fast path
+	return colony.Tunnel{From: fromRoom.Name, To: toRoom.Name, Directed: separator == "->"}, nil
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile selects which extensions of the map format the parser accepts,
+// and what it keeps of the input. Every entry point takes one, so a single
+// value decides how strict a parse is.
+type Profile struct {
+	AllowLeadingL          bool // room names may start with L, even though moves then read ambiguously
+	AllowComments          bool // lines starting with # other than ##start and ##end are skipped
+	RequireCoordinates     bool // rooms must be "name x y"; otherwise "name" alone is a room at 0,0
+	AllowForwardTunnels    bool // tunnels may name rooms defined further down, e.g. a start room amid the tunnels
+	DropDuplicateTunnels   bool // a tunnel given twice is kept once instead of rejected
+	AllowTunnelCapacity    bool // "a-b 3" is a tunnel that 3 ants may cross per turn
+	AllowRoomCapacity      bool // "name x y 5" is a room that holds 5 ants at once
+	AllowMultipleTerminals bool // ##start and ##end may be repeated, each marking one more entrance or exit
+	AllowDirectedTunnels   bool // "a->b" is a tunnel that ants may only cross from a to b
+	AllowZones             bool // "##zone name room..." groups rooms; otherwise it is a comment
+	RequirePath            bool // the end must be reachable from the start, which solving needs
+	KeepRaw                bool // keep the input bytes in Colony.Raw when parsing bytes, files or readers
+}
+
+var (
+	// Strict01Edu is the format of the 01-edu subject
+	Strict01Edu = Profile{AllowComments: true, RequireCoordinates: true}
+
+	// Lenient accepts every extension of the format
+	Lenient = Profile{AllowLeadingL: true, AllowComments: true, AllowForwardTunnels: true, DropDuplicateTunnels: true, AllowTunnelCapacity: true, AllowRoomCapacity: true, AllowMultipleTerminals: true, AllowDirectedTunnels: true, AllowZones: true}
+)
+
+var profiles = map[string]Profile{
+	"strict":  Strict01Edu,
+	"lenient": Lenient,
+}
+
+// ProfileNames returns the names accepted by LookupProfile
+func ProfileNames() []string {
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupProfile returns the preset with the given name, for command-line flags
+func LookupProfile(name string) (Profile, error) {
+	profile, ok := profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(ProfileNames(), ", "))
+	}
+	return profile, nil
+}
@@ -0,0 +1,29 @@
+package parser
+
+import (
+	"io"
+	"os"
+)
+
+// ReadLines returns every line read from r, without line endings. Unlike
+// bufio.Scanner it has no limit on the length of a line.
+func ReadLines(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(data), nil
+}
+
+// ReadFile returns the lines of a file, or of standard input when filename
+// is "-"
+func ReadFile(filename string) ([]string, error) {
+	if filename == "-" {
+		return ReadLines(os.Stdin)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(data), nil
+}
@@ -0,0 +1,15 @@
+package pathfinder
+
+import "errors"
+
+// ErrLimitExceeded is matched, with errors.Is, by the errors of strategies
+// and solvers that give up on a map beyond their size limits
+var ErrLimitExceeded = errors.New("map exceeds the limits of the solver")
+
+// LimitError is the size limit of one strategy or solver being hit. It
+// keeps its own message and matches ErrLimitExceeded.
+type LimitError string
+
+func (e LimitError) Error() string { return string(e) }
+
+func (e LimitError) Is(target error) bool { return target == ErrLimitExceeded }
@@ -0,0 +1,49 @@
+package pathfinder_test
+
+import (
+	"errors"
+	"fmt"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/pathfinder"
+)
+
+// A strategy that only takes the tunnel joining start and end, if any
+func ExampleRegister() {
+	pathfinder.Register("direct", pathfinder.StrategyFunc(func(c *colony.Colony) ([]pathfinder.Path, error) {
+		for _, room := range c.Neighbors(c.Start) {
+			if room == c.End {
+				return []pathfinder.Path{{c.Start, c.End}}, nil
+			}
+		}
+		return nil, nil
+	}))
+
+	c := colony.New()
+	c.Ants, c.Start, c.End = 2, "a", "b"
+	c.Rooms["a"] = &colony.Room{Name: "a"}
+	c.Rooms["b"] = &colony.Room{Name: "b"}
+	c.AddTunnel(colony.Tunnel{From: "a", To: "b"})
+
+	strategy, err := pathfinder.Lookup("direct")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	paths, _ := strategy.FindPaths(c)
+	fmt.Println(paths)
+	_, err = pathfinder.Lookup("missing")
+	fmt.Println(err)
+	// Output:
+	// [[a b]]
+	// unknown strategy "missing" (available: direct)
+}
+
+func ExampleLimitError() {
+	err := fmt.Errorf("dfs: %w", pathfinder.LimitError("path search visited too many rooms"))
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, pathfinder.ErrLimitExceeded))
+	// Output:
+	// dfs: path search visited too many rooms
+	// true
+}
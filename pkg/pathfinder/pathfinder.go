@@ -0,0 +1,105 @@
+// Package pathfinder is the extension point for the first phase of solving
+// a colony: choosing the paths the ants take from start to end. A strategy
+// only returns paths; lem-in still schedules the ants on them, checks the
+// plan and prints it, so a new algorithm needs nothing else.
+//
+// Strategies are registered by name, usually from an init function, and
+// chosen with the --strategy flag of lem-in:
+//
+//	func init() {
+//		pathfinder.Register("mine", pathfinder.StrategyFunc(findMine))
+//	}
+package pathfinder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"lem2/pkg/colony"
+)
+
+// Path is a route from the start room to the end room, given by the names
+// of every room on it, both ends included
+type Path []string
+
+// Strategy chooses the paths of a colony. Paths may share rooms; the
+// scheduler keeps ants from meeting. No paths and a nil error means the end
+// cannot be reached.
+type Strategy interface {
+	FindPaths(c *colony.Colony) ([]Path, error)
+}
+
+// StrategyFunc lets an ordinary function be used as a Strategy
+type StrategyFunc func(c *colony.Colony) ([]Path, error)
+
+// FindPaths calls f(c)
+func (f StrategyFunc) FindPaths(c *colony.Colony) ([]Path, error) {
+	return f(c)
+}
+
+// ContextStrategy is a Strategy whose search can be cut short. When ctx
+// ends, FindPathsCtx returns the paths found so far together with the
+// error of ctx.
+type ContextStrategy interface {
+	Strategy
+	FindPathsCtx(ctx context.Context, c *colony.Colony) ([]Path, error)
+}
+
+// FindPathsCtx finds the paths of c with s, stopping when ctx ends if s
+// is a ContextStrategy. Other strategies run to completion, after which
+// the error of an ended ctx is returned with their paths.
+func FindPathsCtx(ctx context.Context, s Strategy, c *colony.Colony) ([]Path, error) {
+	if cs, ok := s.(ContextStrategy); ok {
+		return cs.FindPathsCtx(ctx, c)
+	}
+	paths, err := s.FindPaths(c)
+	if err == nil {
+		err = ctx.Err()
+	}
+	return paths, err
+}
+
+var (
+	mu         sync.RWMutex
+	strategies = make(map[string]Strategy)
+)
+
+// Register makes a strategy available by name. It panics if the name is
+// taken or s is nil, which are programming errors.
+func Register(name string, s Strategy) {
+	mu.Lock()
+	defer mu.Unlock()
+	if s == nil {
+		panic("pathfinder: Register of a nil strategy " + name)
+	}
+	if _, taken := strategies[name]; taken {
+		panic("pathfinder: Register called twice for strategy " + name)
+	}
+	strategies[name] = s
+}
+
+// Names returns the names of the registered strategies, sorted
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the strategy registered under name
+func Lookup(name string) (Strategy, error) {
+	mu.RLock()
+	s, ok := strategies[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return s, nil
+}
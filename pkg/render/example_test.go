@@ -0,0 +1,50 @@
+package render_test
+
+import (
+	"fmt"
+
+	"lem2/pkg/parser"
+	"lem2/pkg/render"
+	"lem2/pkg/simulator"
+)
+
+func ExampleFrames() {
+	c, err := parser.ParseLines([]string{
+		"2",
+		"##start",
+		"a 0 0",
+		"b 1 0",
+		"##end",
+		"c 2 0",
+		"a-b",
+		"b-c",
+	}, parser.Strict01Edu)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	frames := render.Frames(c, [][]simulator.Move{
+		{{Ant: 1, Room: "b"}},
+		{{Ant: 1, Room: "c"}, {Ant: 2, Room: "b"}},
+		{{Ant: 2, Room: "c"}},
+	})
+	for _, path := range frames.Scene().Paths {
+		fmt.Println("path", path.Rooms, path.Color, "ants", path.Ants)
+	}
+	for frame, ok := frames.Next(); ok; frame, ok = frames.Next() {
+		fmt.Printf("turn %d:", frame.Turn)
+		for _, ant := range frame.Ants {
+			fmt.Printf(" %d %s->%s at %d,%d", ant.ID, ant.From, ant.Room, ant.X, ant.Y)
+		}
+		fmt.Println()
+	}
+	fmt.Println(frames.Err())
+	// Output:
+	// path [a b c] #e05252 ants [1 2]
+	// turn 0: 1 a->a at 0,0 2 a->a at 0,0
+	// turn 1: 1 a->b at 1,0 2 a->a at 0,0
+	// turn 2: 1 b->c at 2,0 2 a->b at 1,0
+	// turn 3: 1 c->c at 2,0 2 b->c at 2,0
+	// <nil>
+}
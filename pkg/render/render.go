@@ -0,0 +1,231 @@
+// Package render describes a plan as scenes for renderers outside lem-in:
+// the colony once, with every path in its own color, then where every ant
+// is after each turn. The ants are moved by the simulator package, so a
+// renderer only draws what it is given and never replays the moves.
+package render
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/simulator"
+)
+
+// Room is a room and its coordinates
+type Room struct {
+	Name string `json:"name"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+}
+
+// Tunnel joins two rooms
+type Tunnel struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Zone is a named group of rooms (##zone)
+type Zone struct {
+	Name  string   `json:"name"`
+	Rooms []string `json:"rooms"`
+}
+
+// Path is a route taken by ants from start to end
+type Path struct {
+	Rooms []string `json:"rooms"`
+	Color string   `json:"color"` // "#rrggbb"
+	Ants  []int    `json:"ants"`
+}
+
+// Scene is the part of the picture that does not change between turns
+type Scene struct {
+	Rooms   []Room   `json:"rooms"`
+	Tunnels []Tunnel `json:"tunnels"`
+	Start   string   `json:"start"`
+	End     string   `json:"end"`
+	Paths   []Path   `json:"paths"`
+	Zones   []Zone   `json:"zones,omitempty"`
+}
+
+// Ant is an ant in a frame. From is its room in the previous frame, so a
+// renderer can animate the move; it equals Room when the ant waited.
+type Ant struct {
+	ID    int    `json:"id"`
+	Room  string `json:"room"`
+	From  string `json:"from"`
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Path  int    `json:"path"` // index in Scene.Paths, -1 for an ant that never moves
+	Color string `json:"color"`
+}
+
+// Frame is where every ant is after a turn; turn 0 is the start. Zones
+// counts the ants in every zone of the scene, leaving out ants in start
+// and end rooms.
+type Frame struct {
+	Turn  int            `json:"turn"`
+	Ants  []Ant          `json:"ants"`
+	Zones map[string]int `json:"zones,omitempty"`
+}
+
+// FrameIterator walks through the frames of a plan:
+//
+//	frames := render.Frames(c, turns)
+//	for frame, ok := frames.Next(); ok; frame, ok = frames.Next() {
+//		...
+//	}
+//	if err := frames.Err(); err != nil {
+//		...
+//	}
+type FrameIterator struct {
+	c     *colony.Colony
+	sim   *simulator.Simulation
+	scene Scene
+	path  []int // index of the path of every ant
+	prev  []string
+	first bool
+}
+
+// Frames returns an iterator over the frames of turns in c, starting with
+// the frame of turn 0
+func Frames(c *colony.Colony, turns [][]simulator.Move) *FrameIterator {
+	f := &FrameIterator{c: c, sim: simulator.New(c, turns), first: true}
+	f.scene, f.path = scene(c, turns)
+	f.prev = f.sim.State().Positions
+	return f
+}
+
+// Scene returns the rooms, tunnels and paths of the plan
+func (f *FrameIterator) Scene() Scene {
+	return f.scene
+}
+
+// Next returns the next frame. It returns false after the last turn or
+// when a move breaks a rule of the simulation, which Err then returns.
+func (f *FrameIterator) Next() (Frame, bool) {
+	if f.first {
+		f.first = false
+		return f.frame(0, f.prev), true
+	}
+	if _, ok := f.sim.Step(); !ok {
+		return Frame{}, false
+	}
+	state := f.sim.State()
+	frame := f.frame(state.Turn, state.Positions)
+	f.prev = state.Positions
+	return frame, true
+}
+
+// Err returns the rule broken by a move, or nil
+func (f *FrameIterator) Err() error {
+	return f.sim.Err()
+}
+
+func (f *FrameIterator) frame(turn int, positions []string) Frame {
+	frame := Frame{Turn: turn, Ants: make([]Ant, 0, len(positions)-1)}
+	for ant := 1; ant < len(positions); ant++ {
+		room := f.c.Rooms[positions[ant]]
+		a := Ant{ID: ant, Room: room.Name, From: f.prev[ant], X: room.X, Y: room.Y, Path: f.path[ant]}
+		if a.Path >= 0 {
+			a.Color = f.scene.Paths[a.Path].Color
+		}
+		frame.Ants = append(frame.Ants, a)
+	}
+	if len(f.scene.Zones) > 0 {
+		frame.Zones = make(map[string]int, len(f.scene.Zones))
+		for _, zone := range f.scene.Zones {
+			frame.Zones[zone.Name] = 0
+		}
+		for _, room := range positions[1:] {
+			if zone := f.c.ZoneOf(room); zone != "" && !f.c.IsStart(room) && !f.c.IsEnd(room) {
+				frame.Zones[zone]++
+			}
+		}
+	}
+	return frame
+}
+
+// scene describes c, with the rooms sorted by name, and the distinct
+// routes of the ants, in the order the first ant on each leaves, along
+// with the route of every ant
+func scene(c *colony.Colony, turns [][]simulator.Move) (Scene, []int) {
+	s := Scene{Tunnels: make([]Tunnel, len(c.Tunnels)), Start: c.Start, End: c.End, Paths: []Path{}}
+	for i, t := range c.Tunnels {
+		s.Tunnels[i] = Tunnel{From: t.From, To: t.To}
+	}
+	for _, room := range c.Rooms {
+		s.Rooms = append(s.Rooms, Room{Name: room.Name, X: room.X, Y: room.Y})
+	}
+	sort.Slice(s.Rooms, func(i, j int) bool { return s.Rooms[i].Name < s.Rooms[j].Name })
+	for _, zone := range c.Zones {
+		s.Zones = append(s.Zones, Zone{Name: zone.Name, Rooms: zone.Rooms})
+	}
+
+	routes := make([][]string, c.Ants+1)
+	var order []int
+	for _, moves := range turns {
+		for _, move := range moves {
+			if move.Ant < 1 || move.Ant > c.Ants {
+				continue // reported by the simulation
+			}
+			if routes[move.Ant] == nil {
+				routes[move.Ant] = []string{c.Start}
+				order = append(order, move.Ant)
+			}
+			routes[move.Ant] = append(routes[move.Ant], move.Room)
+		}
+	}
+
+	path := make([]int, c.Ants+1)
+	for ant := range path {
+		path[ant] = -1
+	}
+	index := make(map[string]int)
+	for _, ant := range order {
+		key := strings.Join(routes[ant], " ")
+		i, ok := index[key]
+		if !ok {
+			i = len(s.Paths)
+			index[key] = i
+			s.Paths = append(s.Paths, Path{Rooms: routes[ant], Color: PathColor(i)})
+		}
+		s.Paths[i].Ants = append(s.Paths[i].Ants, ant)
+		path[ant] = i
+	}
+	return s, path
+}
+
+// PathColor returns the color of the path with the given index. Hues are
+// spread around the circle by the golden angle, so paths with neighboring
+// indices never look alike.
+func PathColor(i int) string {
+	hue := math.Mod(float64(i)*137.508, 360)
+	r, g, b := hslToRGB(hue, 0.7, 0.6)
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+func hslToRGB(h, s, l float64) (r, g, b uint8) {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var rf, gf, bf float64
+	switch {
+	case h < 60:
+		rf, gf = c, x
+	case h < 120:
+		rf, gf = x, c
+	case h < 180:
+		gf, bf = c, x
+	case h < 240:
+		gf, bf = x, c
+	case h < 300:
+		rf, bf = x, c
+	default:
+		rf, bf = c, x
+	}
+	scale := func(v float64) uint8 { return uint8(math.Round((v + m) * 255)) }
+	return scale(rf), scale(gf), scale(bf)
+}
@@ -0,0 +1,122 @@
+package simulator_test
+
+import (
+	"fmt"
+
+	"lem2/pkg/parser"
+	"lem2/pkg/simulator"
+)
+
+func ExampleSimulation() {
+	c, err := parser.ParseLines([]string{
+		"2",
+		"##start",
+		"a 0 0",
+		"b 1 0",
+		"##end",
+		"c 2 0",
+		"a-b",
+		"b-c",
+	}, parser.Strict01Edu)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	sim := simulator.New(c, [][]simulator.Move{
+		{{Ant: 1, Room: "b"}},
+		{{Ant: 1, Room: "c"}, {Ant: 2, Room: "b"}},
+		{{Ant: 2, Room: "b"}},
+	})
+	for {
+		result, ok := sim.Step()
+		if !ok {
+			break
+		}
+		state := sim.State()
+		fmt.Printf("turn %d: arrived %v, positions %v\n", result.Turn, result.Arrived, state.Positions[1:])
+	}
+	fmt.Println(sim.Err())
+	// Output:
+	// turn 1: arrived [], positions [b a]
+	// turn 2: arrived [1], positions [c b]
+	// turn 3: no tunnel from b to b for ant 2
+}
+
+func ExampleSimulation_capacity() {
+	c, err := parser.ParseLines([]string{
+		"3",
+		"##start",
+		"a 0 0",
+		"##end",
+		"b 1 0",
+		"a-b 2",
+	}, parser.Lenient)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	sim := simulator.New(c, [][]simulator.Move{
+		{{Ant: 1, Room: "b"}, {Ant: 2, Room: "b"}},
+		{{Ant: 3, Room: "b"}},
+	})
+	for {
+		if _, ok := sim.Step(); !ok {
+			break
+		}
+	}
+	fmt.Println(sim.State().AtEnd, sim.Err())
+
+	sim = simulator.New(c, [][]simulator.Move{
+		{{Ant: 1, Room: "b"}, {Ant: 2, Room: "b"}, {Ant: 3, Room: "b"}},
+	})
+	sim.Step()
+	fmt.Println(sim.Err())
+	// Output:
+	// 3 <nil>
+	// turn 1: tunnel a-b used by more than 2 ants
+}
+
+func ExampleSimulation_roomCapacity() {
+	c, err := parser.ParseLines([]string{
+		"3",
+		"##start",
+		"a 0 0",
+		"hall 1 0 2",
+		"##end",
+		"b 2 0",
+		"a-hall 2",
+		"hall-b 2",
+	}, parser.Lenient)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	sim := simulator.New(c, [][]simulator.Move{
+		{{Ant: 1, Room: "hall"}, {Ant: 2, Room: "hall"}},
+		{{Ant: 1, Room: "b"}, {Ant: 2, Room: "b"}, {Ant: 3, Room: "hall"}},
+		{{Ant: 3, Room: "b"}},
+	})
+	for {
+		if _, ok := sim.Step(); !ok {
+			break
+		}
+	}
+	fmt.Println(sim.State().AtEnd, sim.Err())
+
+	sim = simulator.New(c, [][]simulator.Move{
+		{{Ant: 1, Room: "hall"}, {Ant: 2, Room: "hall"}},
+		{{Ant: 3, Room: "hall"}},
+	})
+	for {
+		if _, ok := sim.Step(); !ok {
+			break
+		}
+	}
+	fmt.Println(sim.Err())
+	// Output:
+	// 3 <nil>
+	// turn 2: room hall holds more than 2 ants
+}
@@ -0,0 +1,192 @@
+// Package simulator moves ants through a colony one turn at a time,
+// enforcing the rules of the simulation, including tunnel and room
+// capacities, directed tunnels and several start and end rooms, so
+// visualizers and tests can drive a plan step by step and look at where
+// every ant is in between.
+package simulator
+
+import (
+	"fmt"
+
+	"lem2/pkg/colony"
+)
+
+// Move sends an ant into a room
+type Move struct {
+	Ant  int
+	Room string
+}
+
+// TurnResult is what happened in one turn
+type TurnResult struct {
+	Turn    int    // from 1
+	Moves   []Move // as given
+	Arrived []int  // ants that entered the end room, in order of the moves
+}
+
+// State is where the ants are after a number of turns
+type State struct {
+	Turn      int
+	Positions []string // room of every ant, indexed by ant; index 0 is unused
+	AtStart   int
+	AtEnd     int
+}
+
+// Error is a broken rule. Move is the index of the offending move in its
+// turn, or -1 when the turn as a whole breaks a rule, such as two ants
+// ending up in the same room.
+type Error struct {
+	Turn    int
+	Move    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("turn %d: %s", e.Turn, e.Message)
+}
+
+// Simulation replays turns of moves for the ants of a colony, which start
+// in the start room. When the colony has several start rooms, ants in any
+// of them may leave by any of them; ants finish in any end room.
+type Simulation struct {
+	c        *colony.Colony
+	turns    [][]Move
+	turn     int
+	position []string
+	err      error
+}
+
+// New returns a simulation of turns in c before the first turn
+func New(c *colony.Colony, turns [][]Move) *Simulation {
+	position := make([]string, c.Ants+1)
+	for ant := 1; ant <= c.Ants; ant++ {
+		position[ant] = c.Start
+	}
+	return &Simulation{c: c, turns: turns, position: position}
+}
+
+// Step plays the next turn. It returns false once every turn has been
+// played or when a move breaks a rule; Err tells the two apart. A turn
+// that breaks a rule is not applied.
+func (s *Simulation) Step() (TurnResult, bool) {
+	if s.err != nil || s.turn >= len(s.turns) {
+		return TurnResult{}, false
+	}
+	c, number, moves := s.c, s.turn+1, s.turns[s.turn]
+	result := TurnResult{Turn: number, Moves: moves}
+	next := append([]string(nil), s.position...)
+
+	moved := make(map[int]bool)
+	used := make(map[[2]string]int) // ants crossing every tunnel
+	for i, move := range moves {
+		fail := func(format string, args ...any) (TurnResult, bool) {
+			s.err = &Error{Turn: number, Move: i, Message: fmt.Sprintf(format, args...)}
+			return TurnResult{}, false
+		}
+
+		if move.Ant < 1 || move.Ant > c.Ants {
+			return fail("unknown ant %d", move.Ant)
+		}
+		if c.Rooms[move.Room] == nil {
+			return fail("unknown room %s", move.Room)
+		}
+		if moved[move.Ant] {
+			return fail("ant %d moves twice", move.Ant)
+		}
+		from := next[move.Ant]
+		if c.IsEnd(from) {
+			return fail("ant %d moves after reaching the end", move.Ant)
+		}
+		if c.IsStart(from) {
+			// Ants in a start room may leave by any of them
+			from = entrance(c, move.Room)
+		}
+		if !connected(c, from, move.Room) {
+			return fail("no tunnel from %s to %s for ant %d", from, move.Room, move.Ant)
+		}
+		if !c.Leads(from, move.Room) {
+			return fail("tunnel %s->%s is taken backwards by ant %d", move.Room, from, move.Ant)
+		}
+		tunnel := [2]string{min(from, move.Room), max(from, move.Room)}
+		used[tunnel]++
+		if capacity := c.TunnelCapacity(from, move.Room); used[tunnel] > capacity {
+			if capacity == 1 {
+				return fail("tunnel %s-%s used twice", from, move.Room)
+			}
+			return fail("tunnel %s-%s used by more than %d ants", from, move.Room, capacity)
+		}
+		moved[move.Ant] = true
+		next[move.Ant] = move.Room
+		if c.IsEnd(move.Room) {
+			result.Arrived = append(result.Arrived, move.Ant)
+		}
+	}
+
+	occupants := make(map[string][]int) // ants in every room but start and end
+	inZone := make(map[string]int)
+	for ant := 1; ant <= c.Ants; ant++ {
+		room := next[ant]
+		if c.IsStart(room) || c.IsEnd(room) {
+			continue
+		}
+		occupants[room] = append(occupants[room], ant)
+		if capacity := c.RoomCapacity(room); len(occupants[room]) > capacity {
+			message := fmt.Sprintf("ants %d and %d share room %s", occupants[room][0], ant, room)
+			if capacity > 1 {
+				message = fmt.Sprintf("room %s holds more than %d ants", room, capacity)
+			}
+			s.err = &Error{Turn: number, Move: -1, Message: message}
+			return TurnResult{}, false
+		}
+		if zone := c.ZoneOf(room); zone != "" {
+			inZone[zone]++
+			if capacity := c.ZoneCapacity(zone); capacity > 0 && inZone[zone] > capacity {
+				s.err = &Error{Turn: number, Move: -1, Message: fmt.Sprintf("zone %s holds more than %d ants", zone, capacity)}
+				return TurnResult{}, false
+			}
+		}
+	}
+
+	s.turn++
+	s.position = next
+	return result, true
+}
+
+// State returns the positions after the turns played so far
+func (s *Simulation) State() State {
+	state := State{Turn: s.turn, Positions: append([]string(nil), s.position...)}
+	for ant := 1; ant < len(s.position); ant++ {
+		switch room := s.position[ant]; {
+		case s.c.IsEnd(room):
+			state.AtEnd++
+		case s.c.IsStart(room):
+			state.AtStart++
+		}
+	}
+	return state
+}
+
+// Err returns the rule broken by the last step, or nil
+func (s *Simulation) Err() error {
+	return s.err
+}
+
+// entrance returns the start room next to room, or the first start room
+// when none is
+func entrance(c *colony.Colony, room string) string {
+	for _, start := range c.StartRooms() {
+		if connected(c, start, room) && c.Leads(start, room) {
+			return start
+		}
+	}
+	return c.Start
+}
+
+func connected(c *colony.Colony, a, b string) bool {
+	for _, neighbor := range c.Neighbors(a) {
+		if neighbor == b {
+			return true
+		}
+	}
+	return false
+}
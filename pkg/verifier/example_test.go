@@ -0,0 +1,33 @@
+package verifier_test
+
+import (
+	"fmt"
+
+	"lem2/pkg/parser"
+	"lem2/pkg/verifier"
+)
+
+func ExampleVerify() {
+	c, err := parser.ParseLines([]string{
+		"2",
+		"##start",
+		"a 0 0",
+		"b 1 0",
+		"##end",
+		"c 2 0",
+		"a-b",
+		"b-c",
+	}, parser.Strict01Edu)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(verifier.Verify(c, []string{"L1-b", "L1-c L2-b", "L2-c"}))
+	fmt.Println(verifier.Verify(c, []string{"L1-b L2-b", "L1-c L2-c"}))
+	fmt.Println(verifier.Verify(c, []string{"L1-c"}))
+	// Output:
+	// <nil>
+	// line 1 (turn 1): tunnel a-b used twice
+	// line 1 (turn 1): no tunnel from a to c for ant 1
+}
@@ -0,0 +1,163 @@
+// Package verifier checks the moves printed for a colony against the rules
+// of the simulation, independently of how they were computed, so graders
+// and CI can validate outputs of any solver.
+package verifier
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/simulator"
+)
+
+// Error is a broken rule. Line is the 1-based line of the moves where it
+// was found, or 0 for problems found after the last turn.
+type Error struct {
+	Line    int
+	Turn    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("line %d (turn %d): %s", e.Line, e.Turn, e.Message)
+}
+
+// StripEcho returns the move lines of a complete output: when output starts
+// with the lines of the map, followed by comment lines such as the ones of
+// --header and an empty line, they are dropped.
+func StripEcho(c *colony.Colony, output []string) []string {
+	if len(output) <= len(c.Input) {
+		return output
+	}
+	for i, line := range c.Input {
+		if output[i] != line {
+			return output
+		}
+	}
+	for i := len(c.Input); i < len(output); i++ {
+		switch {
+		case output[i] == "":
+			return output[i+1:]
+		case !strings.HasPrefix(output[i], "#"):
+			return output
+		}
+	}
+	return output
+}
+
+// Verify replays the move lines against c with a simulator.Simulation,
+// which checks that ants only move along existing tunnels, at most once
+// per turn and never after reaching the end, and that no room other than
+// start and end holds more ants at the end of a turn, nor any tunnel is
+// crossed by more ants within a turn, than its capacity, 1 unless the map
+// says otherwise. Verify then checks that every ant reaches the end.
+//
+// Every line holds the "L<ant>-<room>" moves of one turn. A "# turn <n>"
+// comment line starts a turn whose moves follow one per line instead;
+// other comments are ignored.
+func Verify(c *colony.Colony, lines []string) error {
+	// Moves are replayed up to the first one that cannot be read, so a
+	// broken rule in an earlier move is still reported first
+	turns := splitTurns(lines)
+	var moves [][]simulator.Move
+	var syntax *Error
+	for _, turn := range turns {
+		var parsed []simulator.Move
+		for _, move := range turn.moves {
+			ant, room, ok := parseMove(move.text)
+			if !ok {
+				syntax = &Error{Line: move.line, Turn: turn.number, Message: fmt.Sprintf("invalid move %q", move.text)}
+				break
+			}
+			parsed = append(parsed, simulator.Move{Ant: ant, Room: room})
+		}
+		moves = append(moves, parsed)
+		if syntax != nil {
+			break
+		}
+	}
+
+	sim := simulator.New(c, moves)
+	for {
+		if _, ok := sim.Step(); !ok {
+			break
+		}
+	}
+	var broken *simulator.Error
+	// A room shared at the end of a turn cut short by an unreadable move
+	// is not known to be shared
+	if errors.As(sim.Err(), &broken) && (syntax == nil || broken.Turn < syntax.Turn || broken.Move >= 0) {
+		turn := turns[broken.Turn-1]
+		line := turn.line
+		if broken.Move >= 0 {
+			line = turn.moves[broken.Move].line
+		}
+		return &Error{Line: line, Turn: broken.Turn, Message: broken.Message}
+	}
+	if syntax != nil {
+		return syntax
+	}
+
+	for ant, room := range sim.State().Positions {
+		if ant > 0 && !c.IsEnd(room) {
+			return &Error{Message: fmt.Sprintf("ant %d never reaches the end", ant)}
+		}
+	}
+	return nil
+}
+
+type move struct {
+	text string
+	line int
+}
+
+type turn struct {
+	number int
+	line   int // line of the last move, where room conflicts are reported
+	moves  []move
+}
+
+// splitTurns groups the moves of lines into turns
+func splitTurns(lines []string) []turn {
+	var turns []turn
+	perLine := false // inside a "# turn <n>" block
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case strings.HasPrefix(line, "# turn "):
+			turns = append(turns, turn{number: len(turns) + 1, line: i + 1})
+			perLine = true
+			continue
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		}
+		if !perLine {
+			turns = append(turns, turn{number: len(turns) + 1})
+		}
+		t := &turns[len(turns)-1]
+		t.line = i + 1
+		for _, text := range strings.Fields(line) {
+			t.moves = append(t.moves, move{text: text, line: i + 1})
+		}
+	}
+	return turns
+}
+
+// parseMove splits "L<ant>-<room>"
+func parseMove(text string) (int, string, bool) {
+	antText, room, ok := strings.Cut(strings.TrimPrefix(text, "L"), "-")
+	if !ok || !strings.HasPrefix(text, "L") || room == "" {
+		return 0, "", false
+	}
+	ant, err := strconv.Atoi(antText)
+	if err != nil {
+		return 0, "", false
+	}
+	return ant, room, true
+}
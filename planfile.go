@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Binary plan files start with planMagic and a version byte, followed by
+// the kind of content, the room names in ID order and the start and end
+// rooms. A path set then lists its paths as room indices; a plan lists its
+// turns as (ant, room index) moves. Every number is an unsigned varint, so
+// the encoding does not depend on the byte order of the machine.
+//
+// planVersion is bumped whenever the layout changes. Readers reject files
+// from a newer version instead of misreading them.
+const (
+	planMagic   = "LEMP"
+	planVersion = 1
+
+	kindPathSet = 'P'
+	kindPlan    = 'T'
+)
+
+var errNotPlan = errors.New("not a lem-in plan file")
+
+// planFile is a decoded plan file. Room numbers index names, which need not
+// match the room IDs of the map the file is replayed against.
+type planFile struct {
+	kind       byte
+	names      []string
+	start, end int
+	paths      [][]int  // path sets only
+	turns      [][]Move // plans only
+}
+
+type planWriter struct {
+	buf []byte
+}
+
+func (w *planWriter) uint(n int) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(n))
+}
+
+func (w *planWriter) string(s string) {
+	w.uint(len(s))
+	w.buf = append(w.buf, s...)
+}
+
+func newPlanWriter(kind byte, g *Graph, start, end int) *planWriter {
+	w := &planWriter{buf: append([]byte(planMagic), planVersion, kind)}
+	w.uint(len(g.names))
+	for _, name := range g.names {
+		w.string(name)
+	}
+	w.uint(start)
+	w.uint(end)
+	return w
+}
+
+// encodePathSet writes a path set in the binary plan format
+func encodePathSet(ps *PathSet) []byte {
+	w := newPlanWriter(kindPathSet, ps.Graph, ps.Start, ps.End)
+	w.uint(len(ps.Paths))
+	for _, path := range ps.Paths {
+		w.uint(len(path))
+		for _, room := range path {
+			w.uint(room)
+		}
+	}
+	return w.buf
+}
+
+// encodePlan writes the schedule of a solution in the binary plan format
+func encodePlan(s *Solution) []byte {
+	w := newPlanWriter(kindPlan, s.Graph, s.Start, s.End)
+	w.uint(len(s.Turns))
+	for _, moves := range s.Turns {
+		w.uint(len(moves))
+		for _, move := range moves {
+			w.uint(move.Ant)
+			w.uint(move.Room)
+		}
+	}
+	return w.buf
+}
+
+type planReader struct {
+	r     *bytes.Reader
+	rooms int
+	err   error
+}
+
+func (r *planReader) uint() int {
+	if r.err != nil {
+		return 0
+	}
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil || n > 1<<62 {
+		r.err = errors.New("truncated or corrupt plan file")
+		return 0
+	}
+	return int(n)
+}
+
+// count reads the length of a list. Every element takes at least one byte,
+// so a corrupt length cannot make the reader allocate more than the file.
+func (r *planReader) count() int {
+	n := r.uint()
+	if r.err == nil && n > r.r.Len() {
+		r.err = errors.New("truncated or corrupt plan file")
+		return 0
+	}
+	return n
+}
+
+// room reads a room index and checks it against the room table
+func (r *planReader) room() int {
+	room := r.uint()
+	if r.err == nil && room >= r.rooms {
+		r.err = fmt.Errorf("room index %d out of range", room)
+	}
+	return room
+}
+
+func (r *planReader) string() string {
+	s := make([]byte, r.count())
+	r.r.Read(s)
+	return string(s)
+}
+
+// decodePlanFile reads a path set or plan written by encodePathSet or
+// encodePlan
+func decodePlanFile(data []byte) (*planFile, error) {
+	if len(data) < len(planMagic)+2 || string(data[:len(planMagic)]) != planMagic {
+		return nil, errNotPlan
+	}
+	if version := data[len(planMagic)]; version != planVersion {
+		if version > planVersion {
+			return nil, fmt.Errorf("plan file version %d is newer than the supported version %d", version, planVersion)
+		}
+		return nil, fmt.Errorf("plan file version %d is no longer supported (current version %d)", version, planVersion)
+	}
+
+	p := &planFile{kind: data[len(planMagic)+1]}
+	r := &planReader{r: bytes.NewReader(data[len(planMagic)+2:])}
+	p.names = make([]string, r.count())
+	for i := range p.names {
+		p.names[i] = r.string()
+	}
+	r.rooms = len(p.names)
+	p.start, p.end = r.room(), r.room()
+
+	switch p.kind {
+	case kindPathSet:
+		p.paths = make([][]int, r.count())
+		for i := range p.paths {
+			p.paths[i] = make([]int, r.count())
+			for j := range p.paths[i] {
+				p.paths[i][j] = r.room()
+			}
+		}
+	case kindPlan:
+		p.turns = make([][]Move, r.count())
+		for i := range p.turns {
+			p.turns[i] = make([]Move, r.count())
+			for j := range p.turns[i] {
+				p.turns[i][j] = Move{Ant: r.uint(), Room: r.room()}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown plan file content %q", p.kind)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.r.Len() != 0 {
+		return nil, errors.New("trailing data after the plan")
+	}
+	for i, path := range p.paths {
+		// A single room only leads from start to end when it is both
+		if len(path) == 0 || path[0] != p.start || path[len(path)-1] != p.end || len(path) == 1 && p.start != p.end {
+			return nil, fmt.Errorf("path %d does not lead from the start room to the end room", i+1)
+		}
+	}
+	return p, nil
+}
+
+// rooms maps the room indices of the file onto the room IDs of g, checking
+// that start and end match
+func (p *planFile) rooms(g *Graph, start, end int) ([]int, error) {
+	ids := make([]int, len(p.names))
+	for i, name := range p.names {
+		id, ok := g.ID(name)
+		if !ok {
+			return nil, fmt.Errorf("the plan uses room %s, which is not in the map", name)
+		}
+		ids[i] = id
+	}
+	if ids[p.start] != start || ids[p.end] != end {
+		return nil, errors.New("the plan was made for other start and end rooms")
+	}
+	return ids, nil
+}
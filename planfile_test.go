@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDecodeBadPaths checks that path sets with a path that is empty or
+// does not lead from start to end are rejected when read, rather than
+// reaching the scheduler
+func TestDecodeBadPaths(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge("s", "a")
+	g.AddEdge("a", "e")
+	s, _ := g.ID("s")
+	a, _ := g.ID("a")
+	e, _ := g.ID("e")
+
+	for name, path := range map[string][]int{"empty": {}, "start only": {s}, "wrong end": {s, a}, "wrong start": {a, e}} {
+		data := encodePathSet(&PathSet{Graph: g, Start: s, End: e, Paths: [][]int{{s, a, e}, path}})
+		if _, err := decodePlanFile(data); err == nil || !strings.Contains(err.Error(), "path 2") {
+			t.Errorf("%s: got error %v, want one about path 2", name, err)
+		}
+	}
+	if _, err := decodePlanFile(encodePathSet(&PathSet{Graph: g, Start: s, End: e, Paths: [][]int{{s, a, e}}})); err != nil {
+		t.Error(err)
+	}
+}
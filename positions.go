@@ -0,0 +1,48 @@
+package main
+
+// snapshotInterval is the number of turns between the snapshots kept by a
+// position index
+const snapshotInterval = 64
+
+// positionIndex tells where every ant is after any turn of a solution
+// without replaying it from the start. It keeps the positions of all ants
+// every snapshotInterval turns, so a lookup replays fewer than that many
+// turns and memory stays linear in the number of ants times turns/64.
+type positionIndex struct {
+	s         *Solution
+	ants      int
+	snapshots [][]int // positions after turn i*snapshotInterval, indexed by ant
+}
+
+// positionIndex builds the index of a solution for the given number of ants
+func (s *Solution) positionIndex(ants int) *positionIndex {
+	x := &positionIndex{s: s, ants: ants}
+	position := make([]int, ants+1)
+	for ant := range position {
+		position[ant] = s.Start
+	}
+	x.snapshots = append(x.snapshots, append([]int(nil), position...))
+	for turn := 1; turn <= len(s.Turns); turn++ {
+		for _, move := range s.Turns[turn-1] {
+			position[move.Ant] = move.Room
+		}
+		if turn%snapshotInterval == 0 {
+			x.snapshots = append(x.snapshots, append([]int(nil), position...))
+		}
+	}
+	return x
+}
+
+// at returns the room of every ant after the given turn, indexed by ant;
+// turn 0 has every ant in the start room. Turns past the end are clamped.
+func (x *positionIndex) at(turn int) []int {
+	turn = max(0, min(turn, len(x.s.Turns)))
+	snapshot := turn / snapshotInterval
+	position := append([]int(nil), x.snapshots[snapshot]...)
+	for t := snapshot*snapshotInterval + 1; t <= turn; t++ {
+		for _, move := range x.s.Turns[t-1] {
+			position[move.Ant] = move.Room
+		}
+	}
+	return position
+}
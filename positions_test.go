@@ -0,0 +1,44 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"lem2/pkg/parser"
+)
+
+// TestPositionIndex compares every lookup, across several snapshots, with
+// replaying the moves from the first turn
+func TestPositionIndex(t *testing.T) {
+	c, err := parser.ParseInput("testdata/bench/small.map", parser.Strict01Edu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Ants = 3 * snapshotInterval
+	solution, err := solveColony(c, []chainStage{{name: "dfs"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(solution.Turns) <= 2*snapshotInterval {
+		t.Fatalf("%d turns do not reach the third snapshot", len(solution.Turns))
+	}
+
+	positions := solution.positionIndex(c.Ants)
+	want := make([]int, c.Ants+1)
+	for ant := range want {
+		want[ant] = solution.Start
+	}
+	for turn := 0; turn <= len(solution.Turns); turn++ {
+		if turn > 0 {
+			for _, move := range solution.Turns[turn-1] {
+				want[move.Ant] = move.Room
+			}
+		}
+		if got := positions.at(turn); !slices.Equal(got, want) {
+			t.Fatalf("turn %d: got %v, want %v", turn, got, want)
+		}
+	}
+	if got := positions.at(len(solution.Turns) + 10); !slices.Equal(got, want) {
+		t.Errorf("past the last turn: got %v, want %v", got, want)
+	}
+}
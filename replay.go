@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+)
+
+// runReplay implements "lem-in replay <map> <plan>": it prints the moves of
+// a binary plan file for the map after checking them. A path set file gets
+// the ants of the map assigned to its paths first.
+func runReplay(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in replay <map> <plan>")
+		return 2
+	}
+
+	c, err := parser.ParseInput(args[0], parser.Strict01Edu)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	p, err := decodePlanFile(data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", args[1]+":", err)
+		return 1
+	}
+
+	graph := graphFromColony(c)
+	start, _ := graph.ID(c.Start)
+	end, _ := graph.ID(c.End)
+	ids, err := p.rooms(graph, start, end)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+
+	solution := &Solution{Graph: graph, Start: start, End: end, Stage: "replay"}
+	switch p.kind {
+	case kindPathSet:
+		ps := &PathSet{Graph: graph, Start: start, End: end}
+		for _, path := range p.paths {
+			mapped := make([]int, len(path))
+			for i, room := range path {
+				mapped[i] = ids[room]
+			}
+			ps.Paths = append(ps.Paths, mapped)
+		}
+		if len(ps.Paths) == 0 {
+			fmt.Fprintln(os.Stderr, "ERROR:", ErrNoPath)
+			return 1
+		}
+		solution = AssignAnts(ps, c.Ants)
+	case kindPlan:
+		for _, moves := range p.turns {
+			mapped := make([]Move, len(moves))
+			for i, move := range moves {
+				mapped[i] = Move{Ant: move.Ant, Room: ids[move.Room]}
+			}
+			solution.Turns = append(solution.Turns, mapped)
+		}
+	}
+
+	if err := validateSchedule(graph, start, end, c.Ants, solution.Turns); err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: invalid plan:", err)
+		return 1
+	}
+	if err := writeSolution(os.Stdout, c, solution); err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	return 0
+}
+
+// savePlanFiles writes the --save-plan and --save-paths files, if requested
+func savePlanFiles(c *colony.Colony, s *Solution, planFile, pathsFile string) error {
+	if planFile != "" {
+		if err := os.WriteFile(planFile, encodePlan(s), 0o644); err != nil {
+			return err
+		}
+	}
+	if pathsFile != "" {
+		ps, err := FindPathSet(c)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(pathsFile, encodePathSet(ps), 0o644)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+// slot is a room or tunnel taken during a specific turn. Room slots have
+// b set to -1; tunnel slots store their rooms with a < b.
+type slot struct {
+	a, b int
+	turn int
+}
+
+func roomSlot(room, turn int) slot {
+	return slot{room, -1, turn}
+}
+
+func tunnelSlot(a, b, turn int) slot {
+	if b < a {
+		a, b = b, a
+	}
+	return slot{a, b, turn}
+}
+
+// reservationTable records which rooms and tunnels are occupied in which
+// turn, so ants on paths sharing rooms never collide. It is also how the
+// schedulers honor every other per-turn limit: the exit, spawn and zone
+// counters reserve the slots of a turn once it is full, and an ant that
+// only takes free slots stays within the limits without the schedulers
+// knowing about them.
+type reservationTable map[slot]bool
+
+// fits reports whether an ant leaving the start room after turn depart can
+// walk the whole path without waiting. The ant enters path[i] on turn depart+i.
+// The first and last rooms (start and end) hold any number of ants, so the
+// end room is only reserved in turns that used up the exit capacity, and
+// the departures from start in turns that used up the spawn rate.
+func (r reservationTable) fits(path []int, depart int) bool {
+	if r[spawnSlot(path[0], depart+1)] {
+		return false
+	}
+	for i := 1; i < len(path); i++ {
+		if r[roomSlot(path[i], depart+i)] {
+			return false
+		}
+		if r[tunnelSlot(path[i-1], path[i], depart+i)] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r reservationTable) reserve(path []int, depart int) {
+	for i := 1; i < len(path); i++ {
+		if i < len(path)-1 {
+			r[roomSlot(path[i], depart+i)] = true
+		}
+		r[tunnelSlot(path[i-1], path[i], depart+i)] = true
+	}
+}
+
+// earliest returns the first departure turn at which path is free
+func (r reservationTable) earliest(path []int, from int) int {
+	depart := from
+	for !r.fits(path, depart) {
+		depart++
+	}
+	return depart
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// Turn rules (--rules) close rooms during some turns, for scenarios such as
+// a tunnel flooding every other turn. The rule file is a Go text/template
+// run once per turn; it writes "close <room>" for every room no ant may be
+// in at the end of that turn. Closing the end room stops ants arriving.
+//
+//	{{if eq (mod .Turn 2) 0}}close r3{{end}}
+//	{{if and (ge .Turn 5) (le .Turn 8)}}close r7 close r8{{end}}
+var turnRules *template.Template
+
+// closedSlots holds the rooms closed by the rules for the map being solved.
+// Schedulers start from it instead of an empty reservation table.
+var closedSlots reservationTable
+
+var errRules = errors.New("turn rules are only supported by the dfs, bounded and flow solvers")
+
+// ruleData is passed to the rule template
+type ruleData struct {
+	Turn int
+	Ants int
+}
+
+var ruleFuncs = template.FuncMap{
+	"add": func(a, b int) int { return a + b },
+	"sub": func(a, b int) int { return a - b },
+	"mul": func(a, b int) int { return a * b },
+	"mod": func(a, b int) int { return a % b },
+}
+
+// loadRules parses a --rules file
+func loadRules(file string) (*template.Template, error) {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return template.New("rules").Funcs(ruleFuncs).Parse(string(text))
+}
+
+// evalRules runs the rules for every turn a plan can reasonably take:
+// every ant walking alone through every room, twice over. Later turns are
+// never closed.
+func evalRules(rules *template.Template, g *Graph, start, end, ants int) (reservationTable, error) {
+	closed := make(reservationTable)
+	horizon := 2 * (len(g.names) + ants)
+	var out bytes.Buffer
+	for turn := 1; turn <= horizon; turn++ {
+		out.Reset()
+		if err := rules.Execute(&out, ruleData{Turn: turn, Ants: ants}); err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(out.String())
+		for i := 0; i < len(fields); i += 2 {
+			if fields[i] != "close" || i+1 == len(fields) {
+				return nil, fmt.Errorf("turn %d: expected \"close <room>\" in the rules output, got %q", turn, strings.Join(fields[i:], " "))
+			}
+			room, ok := g.ID(fields[i+1])
+			if !ok {
+				return nil, fmt.Errorf("turn %d: the rules close unknown room %s", turn, fields[i+1])
+			}
+			if room == start {
+				return nil, fmt.Errorf("turn %d: the rules cannot close the start room", turn)
+			}
+			closed[roomSlot(room, turn)] = true
+		}
+	}
+	return closed, nil
+}
+
+// clone returns a copy of the table; a nil table gives an empty one
+func (r reservationTable) clone() reservationTable {
+	table := make(reservationTable, len(r))
+	for s, reserved := range r {
+		table[s] = reserved
+	}
+	return table
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"lem2/pkg/colony"
+)
+
+// scrubHelp is printed under every frame of --scrub
+const scrubHelp = "n next, p previous, g <turn> jump, f play, r play backward, m bookmark, ] [ next/previous bookmark, q quit"
+
+// scrub is --visualize under the user's control (--scrub): it reads one
+// command per line from in and draws the frame of the turn it selects.
+// Frames come from a position index, so jumping anywhere is as fast as
+// stepping, and playing backward is just stepping down. An empty line
+// repeats "n".
+func (s *Solution) scrub(in io.Reader, w io.Writer, c *colony.Colony, ants int, delay time.Duration) error {
+	grid, err := newFrameGrid(s, c, ants)
+	if err != nil {
+		return err
+	}
+	positions := s.positionIndex(ants)
+	out := bufio.NewWriter(w)
+	last := len(s.Turns)
+	turn := 0
+	var bookmarks []int // sorted
+	message := ""
+
+	show := func(t int) error {
+		turn = t
+		grid.draw(out, turn, positions.at(turn))
+		if len(bookmarks) > 0 {
+			marks := make([]string, len(bookmarks))
+			for i, b := range bookmarks {
+				marks[i] = strconv.Itoa(b)
+			}
+			fmt.Fprintln(out, "\nbookmarks:", strings.Join(marks, ", "))
+		}
+		if message != "" {
+			fmt.Fprintln(out, "\n"+message)
+			message = ""
+		}
+		fmt.Fprint(out, "\n"+scrubHelp+"\n> ")
+		return out.Flush()
+	}
+	play := func(step int) error {
+		for t := turn + step; t >= 0 && t <= last; t += step {
+			time.Sleep(delay)
+			if err := show(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := show(0); err != nil {
+		return err
+	}
+	commands := bufio.NewScanner(in)
+	for commands.Scan() {
+		command, arg, _ := strings.Cut(strings.TrimSpace(commands.Text()), " ")
+		next := turn
+		switch command {
+		case "", "n":
+			next = min(turn+1, last)
+		case "p":
+			next = max(turn-1, 0)
+		case "g":
+			t, err := strconv.Atoi(strings.TrimSpace(arg))
+			if err != nil || t < 0 || t > last {
+				message = fmt.Sprintf("no turn %q: turns go from 0 to %d", arg, last)
+			} else {
+				next = t
+			}
+		case "f":
+			if err := play(1); err != nil {
+				return err
+			}
+			continue
+		case "r":
+			if err := play(-1); err != nil {
+				return err
+			}
+			continue
+		case "m":
+			if i, found := slices.BinarySearch(bookmarks, turn); found {
+				bookmarks = slices.Delete(bookmarks, i, i+1)
+			} else {
+				bookmarks = slices.Insert(bookmarks, i, turn)
+			}
+		case "]":
+			i, found := slices.BinarySearch(bookmarks, turn)
+			if found {
+				i++
+			}
+			if i < len(bookmarks) {
+				next = bookmarks[i]
+			} else {
+				message = "no bookmark after this turn"
+			}
+		case "[":
+			i, _ := slices.BinarySearch(bookmarks, turn)
+			if i > 0 {
+				next = bookmarks[i-1]
+			} else {
+				message = "no bookmark before this turn"
+			}
+		case "q":
+			return nil
+		default:
+			message = "unknown command " + command
+		}
+		if err := show(next); err != nil {
+			return err
+		}
+	}
+	return commands.Err()
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/convert"
+	"lem2/pkg/parser"
+)
+
+//go:embed web/index.html
+var servePage []byte
+
+// runServe implements "lem-in serve <map>": it solves the map and serves a
+// page that animates the plan in the browser. The colony is available as
+// JSON at /colony, in the format of "lem-in convert", its validation
+// report at /report and the paths of the plan, with the ants on each, at
+// /paths. The turns are streamed as Server-Sent Events from /turns, one
+// "turn" event per turn followed by a "done" event. --only-ants and
+// --only-paths leave every other ant out of /paths and /turns, which keeps
+// large plans readable.
+//
+// Further maps can be posted to /solve, which answers with an ID at once
+// and solves them in the background; /solve/{id}/status reports the phase,
+// progress and statistics so far of the solve, for progress bars.
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	algo := flags.String("algo", "dfs", "scheduling algorithm: "+solverNames())
+	delay := flags.Duration("frame-delay", 500*time.Millisecond, "time between streamed turns")
+	onlyAnts := flags.String("only-ants", "", "only show these ants, e.g. 1,4-9")
+	flags.DurationVar(&solveTimeout, "timeout", 0, "stop searching for paths after this long and use the best paths found so far (0: no limit)")
+	onlyPaths := flags.String("only-paths", "", "only show the ants on these paths, numbered as in the legend, e.g. 1,3")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in serve [--addr host:port] [--algo name] [--timeout d] [--frame-delay d] [--only-ants list] [--only-paths list] <map>")
+		return 2
+	}
+	antSelection, err := parseSelection(*onlyAnts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: --only-ants:", err)
+		return 1
+	}
+	pathSelection, err := parseSelection(*onlyPaths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: --only-paths:", err)
+		return 1
+	}
+
+	stages, err := solverStages(*algo, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	c, err := parser.ParseInput(flags.Arg(0), parser.Strict01Edu)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	solution, err := solveColony(c, stages)
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		return 1
+	}
+	colonyJSON, err := convert.ToJSON(c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	reportJSON, err := json.Marshal(c.Validate())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	paths, shown := servedPaths(solution, antSelection, pathSelection)
+	pathsJSON, err := json.Marshal(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(servePage)
+	})
+	mux.HandleFunc("GET /colony", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(colonyJSON)
+	})
+	mux.HandleFunc("GET /report", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(reportJSON)
+	})
+	mux.HandleFunc("GET /paths", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(pathsJSON)
+	})
+	mux.HandleFunc("GET /turns", func(w http.ResponseWriter, r *http.Request) {
+		streamTurns(w, r, c, solution, shown, *delay)
+	})
+	jobs := newSolveJobs(stages)
+	mux.HandleFunc("POST /solve", jobs.handleSolve)
+	mux.HandleFunc("GET /solve/{id}/status", jobs.handleStatus)
+
+	log.Printf("serving %s on http://%s", flags.Arg(0), *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	return 0
+}
+
+// servedPath is a path as served at /paths. Number is its place in the
+// plan, from 1, which stays the same when other paths are left out.
+type servedPath struct {
+	Number int      `json:"number"`
+	Rooms  []string `json:"rooms"`
+	Ants   []int    `json:"ants"`
+}
+
+// servedPaths returns the paths with a shown ant and which printed ant
+// numbers are shown
+func servedPaths(s *Solution, ants, paths selection) ([]servedPath, map[int]bool) {
+	routes, of := s.antRoutes()
+	served := make([]servedPath, len(routes))
+	for i, route := range routes {
+		served[i] = servedPath{Number: i + 1, Rooms: route.Rooms, Ants: []int{}}
+	}
+
+	// Every ant moves unless start is end, so the ants are 1 to len(of)
+	shown := make(map[int]bool)
+	for ant := 1; ant <= len(of); ant++ {
+		i, number := of[ant], antNumber(ant)
+		if ants.has(number) && paths.has(i+1) {
+			shown[number] = true
+			served[i].Ants = append(served[i].Ants, number)
+		}
+	}
+
+	kept := []servedPath{}
+	for _, path := range served {
+		if len(path.Ants) > 0 {
+			kept = append(kept, path)
+		}
+	}
+	return kept, shown
+}
+
+// selection is a list of number ranges such as "1,4-9"; the empty
+// selection has every number
+type selection [][2]int
+
+func parseSelection(spec string) (selection, error) {
+	var sel selection
+	if spec == "" {
+		return sel, nil
+	}
+	for _, part := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		lo, err := strconv.Atoi(from)
+		hi := lo
+		if err == nil && isRange {
+			hi, err = strconv.Atoi(to)
+		}
+		if err != nil || hi < lo {
+			return nil, fmt.Errorf("invalid number or range %q", part)
+		}
+		sel = append(sel, [2]int{lo, hi})
+	}
+	return sel, nil
+}
+
+func (sel selection) has(n int) bool {
+	for _, r := range sel {
+		if r[0] <= n && n <= r[1] {
+			return true
+		}
+	}
+	return len(sel) == 0
+}
+
+// streamTurns sends every turn of the solution as a Server-Sent Event,
+// delay apart, until the client goes away. Only the moves of shown ants
+// are sent.
+func streamTurns(w http.ResponseWriter, r *http.Request, c *colony.Colony, s *Solution, shown map[int]bool, delay time.Duration) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	turns := s.namedTurns()
+	for i, moves := range turns {
+		kept := []namedMove{}
+		for _, move := range moves {
+			if shown[move.Ant] {
+				kept = append(kept, move)
+			}
+		}
+		data, _ := json.Marshal(kept)
+		fmt.Fprintf(w, "event: turn\nid: %d\ndata: %s\n\n", i+1, data)
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+	fmt.Fprintf(w, "event: done\ndata: {\"ants\":%d,\"turns\":%d}\n\n", c.Ants, len(turns))
+	flusher.Flush()
+}
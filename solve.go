@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"lem2/pkg/colony"
+)
+
+// Solution is a schedule for a colony together with the graph that gives
+// meaning to its room IDs
+type Solution struct {
+	Graph      *Graph
+	Start, End int
+	Turns      [][]Move
+	Stage      string // algorithm that produced the schedule
+}
+
+// namedMove is a move with the room name resolved, used in JSON output
+type namedMove struct {
+	Ant  int    `json:"ant"`
+	Room string `json:"room"`
+}
+
+// graphFromColony builds the tunnel graph of a parsed colony. Room IDs
+// follow the sorted room names so they do not depend on map order.
+func graphFromColony(c *colony.Colony) *Graph {
+	names := make([]string, 0, len(c.Rooms))
+	for name := range c.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	graph := NewGraph()
+	for _, name := range names {
+		graph.AddRoom(name)
+	}
+	if c.Starts != nil || c.Ends != nil {
+		graph.mergeTerminals(c, names)
+	} else {
+		// The adjacency list already holds both directions of every
+		// tunnel, in the order the tunnels were read
+		for _, name := range names {
+			a, _ := graph.ID(name)
+			for _, neighbor := range c.Neighbors(name) {
+				b, _ := graph.ID(neighbor)
+				graph.addNeighbor(a, b)
+			}
+		}
+	}
+	graph.zones = c.Zones
+	for _, tunnel := range c.Tunnels {
+		if c.OneWay[[2]string{tunnel.From, tunnel.To}] {
+			if graph.oneWay == nil {
+				graph.oneWay = make(map[[2]int]bool)
+			}
+			graph.oneWay[[2]int{graph.node(c, tunnel.From), graph.node(c, tunnel.To)}] = true
+		}
+	}
+	return graph
+}
+
+// node returns the ID of the named room of c, where the node of c.Start
+// stands for every start room and the node of c.End for every end room
+func (g *Graph) node(c *colony.Colony, name string) int {
+	switch {
+	case c.IsStart(name):
+		name = c.Start
+	case c.IsEnd(name):
+		name = c.End
+	}
+	id, _ := g.ID(name)
+	return id
+}
+
+// mergeTerminals adds the tunnels of a colony with several start or end
+// rooms. The node of c.Start stands for every start room and the node of
+// c.End for every end room, a super-source and a super-sink, so every
+// solver handles such colonies unchanged; the other start and end rooms
+// are left without tunnels. Tunnels that become loops or duplicates are
+// dropped. The rooms next to a start or end room remember it in
+// g.entrances and g.exits, so output can name the room an ant really uses.
+func (g *Graph) mergeTerminals(c *colony.Colony, names []string) {
+	g.exits, g.entrances = make(map[int]int), make(map[int]int)
+	seen := make(map[[2]int]bool)
+	for _, name := range names {
+		a := g.node(c, name)
+		for _, neighbor := range c.Neighbors(name) {
+			b := g.node(c, neighbor)
+			if a == b || seen[[2]int{a, b}] {
+				continue
+			}
+			seen[[2]int{a, b}] = true
+			g.addNeighbor(a, b)
+		}
+		// Ants take the first start or end room among the tunnels of the room
+		for _, neighbor := range c.Neighbors(name) {
+			if _, ok := g.exits[a]; !ok && !c.IsEnd(name) && c.IsEnd(neighbor) && c.Leads(name, neighbor) {
+				g.exits[a], _ = g.ID(neighbor)
+			}
+			if _, ok := g.entrances[a]; !ok && !c.IsStart(name) && c.IsStart(neighbor) && c.Leads(neighbor, name) {
+				g.entrances[a], _ = g.ID(neighbor)
+			}
+		}
+	}
+}
+
+// roomNames returns the name of the room entered by every move of
+// s.Turns. With merged end rooms a move into the end is named after the
+// end room next to the room the ant leaves.
+func (s *Solution) roomNames() [][]string {
+	names := make([][]string, len(s.Turns))
+	position := make(map[int]int)
+	for i, moves := range s.Turns {
+		names[i] = make([]string, len(moves))
+		for j, move := range moves {
+			room := move.Room
+			if from, ok := position[move.Ant]; room == s.End && s.Graph.exits != nil {
+				if !ok {
+					from = s.Start
+				}
+				if exit, ok := s.Graph.exits[from]; ok {
+					room = exit
+				}
+			}
+			names[i][j] = s.Graph.Name(room)
+			position[move.Ant] = move.Room
+		}
+	}
+	return names
+}
+
+// solveColony schedules the ants of a parsed colony with the given stages
+func solveColony(c *colony.Colony, stages []chainStage) (*Solution, error) {
+	graph := graphFromColony(c)
+	start, _ := graph.ID(c.Start)
+	end, _ := graph.ID(c.End)
+
+	var key planKey
+	if cache != nil {
+		key = planKey{graph.topology(start, end), c.Ants, stagesKey(stages)}
+		if plan, ok := cache.plan(key); ok {
+			explainLog.Printf("cache: reusing the plan from %s", plan.stage)
+			solution := &Solution{Graph: graph, Start: start, End: end, Turns: plan.turns, Stage: plan.stage}
+			solution.explainFlow()
+			return solution, nil
+		}
+	}
+
+	turns, stage, err := runChain(graph, start, end, c.Ants, stages)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cache.storePlan(key, cachedPlan{turns: turns, stage: stage})
+	}
+	solution := &Solution{Graph: graph, Start: start, End: end, Turns: turns, Stage: stage}
+	solution.explainFlow()
+	return solution, nil
+}
+
+// namedTurns resolves the room names of every move
+func (s *Solution) namedTurns() [][]namedMove {
+	turns := make([][]namedMove, len(s.Turns))
+	names := s.roomNames()
+	for i, moves := range s.Turns {
+		for j, move := range moves {
+			turns[i] = append(turns[i], namedMove{Ant: antNumber(move.Ant), Room: names[i][j]})
+		}
+	}
+	return turns
+}
+
+// Move layouts of the text output (--move-layout): all moves of a turn on
+// one line, or one move per line below a "# turn <n>" comment line
+const (
+	layoutTurn = "turn"
+	layoutLine = "line"
+)
+
+var moveLayout = layoutTurn
+
+// writeSolution prints the original input followed by the moves in the
+// "L<ant>-<room>" format, laid out as moveLayout says. The echo comes from
+// the lines or bytes kept by the parser, so the input is never read twice
+// and may be a pipe.
+// Output is buffered and written as it is produced; a slow reader simply
+// blocks the writes, and the first write error stops the output.
+func writeSolution(w io.Writer, c *colony.Colony, s *Solution) error {
+	out := bufio.NewWriter(w)
+	if c.Raw != nil {
+		// Kept with --raw-echo, so line endings come out as they went in
+		if _, err := out.Write(c.Raw); err != nil {
+			return err
+		}
+		if len(c.Raw) > 0 && c.Raw[len(c.Raw)-1] != '\n' {
+			out.WriteByte('\n')
+		}
+	} else {
+		for _, line := range c.Input {
+			if _, err := fmt.Fprintln(out, line); err != nil {
+				return err
+			}
+		}
+	}
+	if outputHeader {
+		if err := writeHeader(out, s); err != nil {
+			return err
+		}
+	}
+	if outputCertificate {
+		if err := writeCertificate(out, s); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(out)
+	names := s.roomNames()
+	for i, moves := range s.Turns {
+		if moveLayout == layoutLine {
+			fmt.Fprintf(out, "# turn %d\n", i+1)
+			for j, move := range moves {
+				if _, err := fmt.Fprintln(out, formatMove(move, names[i][j])); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		parts := make([]string, len(moves))
+		for j, move := range moves {
+			parts[j] = formatMove(move, names[i][j])
+		}
+		if _, err := fmt.Fprintln(out, strings.Join(parts, " ")); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+func formatMove(move Move, room string) string {
+	return "L" + antLabel(move.Ant) + "-" + room
+}
+
+func formatTurn(g *Graph, moves []Move) string {
+	parts := make([]string, len(moves))
+	for i, move := range moves {
+		parts[i] = formatMove(move, g.Name(move.Room))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+)
+
+// maxPostedMap bounds the size of a map posted to /solve
+const maxPostedMap = 64 << 20
+
+// solveJobs runs the solves posted to /solve in the background. The solver
+// status is shared by the whole process, so the solves run one at a time
+// and the others wait in line; /solve/{id}/status reports the live status
+// of the running solve and a snapshot taken at the end for finished ones.
+type solveJobs struct {
+	stages []chainStage
+
+	mu   sync.Mutex
+	jobs map[string]*solveJob
+	next int
+
+	running sync.Mutex // held by the solve in progress
+}
+
+// Job states
+const (
+	jobQueued  = "queued"
+	jobSolving = "solving"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+type solveJob struct {
+	mu     sync.Mutex
+	state  string
+	report statusReport // final status, once done or failed
+	err    string
+	stats  *Stats
+}
+
+// jobStatus is the document served at /solve/{id}/status. Stats are the
+// statistics of the plan once the solve is done.
+type jobStatus struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+	statusReport
+	Error string `json:"error,omitempty"`
+	Stats *Stats `json:"stats,omitempty"`
+}
+
+func newSolveJobs(stages []chainStage) *solveJobs {
+	return &solveJobs{stages: stages, jobs: make(map[string]*solveJob)}
+}
+
+// start queues a solve of c and returns its ID
+func (j *solveJobs) start(c *colony.Colony) string {
+	job := &solveJob{state: jobQueued}
+	j.mu.Lock()
+	j.next++
+	id := strconv.Itoa(j.next)
+	j.jobs[id] = job
+	j.mu.Unlock()
+
+	go func() {
+		j.running.Lock()
+		defer j.running.Unlock()
+		job.mu.Lock()
+		job.state = jobSolving
+		job.mu.Unlock()
+
+		status.reset()
+		solution, err := solveColony(c, j.stages)
+
+		job.mu.Lock()
+		defer job.mu.Unlock()
+		job.report = status.report()
+		if err != nil {
+			job.state, job.err = jobFailed, err.Error()
+			return
+		}
+		stats := solution.stats()
+		job.state, job.stats = jobDone, &stats
+		job.report.Progress = 1
+		job.report.BestTurns = len(solution.Turns)
+	}()
+	return id
+}
+
+// status returns the status of the job with the given ID
+func (j *solveJobs) status(id string) (jobStatus, bool) {
+	j.mu.Lock()
+	job, ok := j.jobs[id]
+	j.mu.Unlock()
+	if !ok {
+		return jobStatus{}, false
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	st := jobStatus{ID: id, State: job.state, Error: job.err, Stats: job.stats}
+	switch job.state {
+	case jobQueued:
+		st.Phase = "waiting for the solves ahead"
+	case jobSolving:
+		st.statusReport = status.report()
+	default:
+		st.statusReport = job.report
+	}
+	return st, true
+}
+
+// handleSolve serves POST /solve: the body is a map, which is parsed at
+// once and solved in the background. The reply names the status URL.
+func (j *solveJobs) handleSolve(w http.ResponseWriter, r *http.Request) {
+	profile := parser.Strict01Edu
+	profile.RequirePath = true
+	c, err := parser.ParseReader(http.MaxBytesReader(w, r.Body, maxPostedMap), profile)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := j.start(c)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/solve/"+id+"/status")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"id": id, "status": "/solve/" + id + "/status"})
+}
+
+// handleStatus serves GET /solve/{id}/status
+func (j *solveJobs) handleStatus(w http.ResponseWriter, r *http.Request) {
+	st, ok := j.status(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSolveStatus posts a map to /solve and follows /solve/{id}/status
+// until the solve is done
+func TestSolveStatus(t *testing.T) {
+	jobs := newSolveJobs([]chainStage{{name: "dfs"}})
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /solve", jobs.handleSolve)
+	mux.HandleFunc("GET /solve/{id}/status", jobs.handleStatus)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	data, err := os.ReadFile("testdata/bench/small.map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(server.URL+"/solve", "text/plain", strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var started struct{ ID, Status string }
+	json.NewDecoder(resp.Body).Decode(&started)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || started.Status != "/solve/1/status" {
+		t.Fatalf("got %s and %+v", resp.Status, started)
+	}
+
+	var st jobStatus
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		resp, err := http.Get(server.URL + started.Status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewDecoder(resp.Body).Decode(&st)
+		resp.Body.Close()
+		if st.State == jobDone || st.State == jobFailed || time.Now().After(deadline) {
+			break
+		}
+	}
+	if st.State != jobDone || st.Progress != 1 || st.Stats == nil || st.BestTurns != st.Stats.Turns {
+		t.Errorf("got %+v", st)
+	}
+
+	resp, err = http.Get(server.URL + "/solve/2/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown solve: got %s, want %d", resp.Status, http.StatusNotFound)
+	}
+	resp, err = http.Post(server.URL+"/solve", "text/plain", strings.NewReader("nonsense"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("posting an invalid map: got %s, want %d", resp.Status, http.StatusBadRequest)
+	}
+}
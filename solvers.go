@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sort"
+	"strings"
+)
+
+// explainLog receives the reasoning behind algorithm decisions (--explain)
+var explainLog = log.New(io.Discard, "", 0)
+
+// explaining reports whether --explain is on, for explanations that are
+// expensive to compute
+func explaining() bool {
+	return explainLog.Writer() != io.Discard
+}
+
+// solver computes a schedule for ants travelling from start to end
+type solver func(ctx context.Context, g *Graph, start, end, ants int) ([][]Move, error)
+
+var solvers = map[string]solver{
+	"dfs":     solveDFS,
+	"bounded": solveBounded,
+	"exact":   solveExact,
+	"cbs":     solveCBS,
+	"flow":    solveFlow,
+	"auto":    solveAuto,
+}
+
+func solverNames() string {
+	return strings.Join(sortedSolvers(), ", ")
+}
+
+// sortedSolvers returns the names of every solver in a stable order
+func sortedSolvers() []string {
+	names := make([]string, 0, len(solvers))
+	for name := range solvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func solveDFS(ctx context.Context, g *Graph, start, end, ants int) ([][]Move, error) {
+	paths, err := g.FindPaths(ctx, start, end, 0)
+	if err != nil {
+		return partialPlan(g, paths, ants, err)
+	}
+	for _, path := range paths {
+		explainLog.Println("dfs: path", g.PathNames(path))
+	}
+	return schedule(g, paths, ants), nil
+}
+
+func solveExact(ctx context.Context, g *Graph, start, end, ants int) ([][]Move, error) {
+	return g.ExactSchedule(ctx, start, end, ants)
+}
+
+func solveCBS(ctx context.Context, g *Graph, start, end, ants int) ([][]Move, error) {
+	return g.CBSSchedule(ctx, start, end, ants)
+}
+
+// solveFlow schedules the ants on vertex-disjoint paths found by max-flow,
+// which stays fast on dense maps where enumerating every path explodes
+func solveFlow(ctx context.Context, g *Graph, start, end, ants int) ([][]Move, error) {
+	paths, err := g.disjointPaths(ctx, start, end, ants)
+	if err != nil {
+		return partialPlan(g, paths, ants, err)
+	}
+	if len(paths) == 0 {
+		return nil, ErrNoPath
+	}
+	for _, path := range paths {
+		explainLog.Println("flow: path", g.PathNames(path))
+	}
+	return schedule(g, paths, ants), nil
+}
+
+// partialPlan schedules the ants on the paths a search found before its
+// context ended and returns the plan along with err; runChain only keeps
+// it when --timeout ended the search. Other errors return no plan. A cut
+// search may have found a huge number of paths, so like solveBounded only
+// the heuristics.BoundedPaths shortest ones are used, which keeps the
+// scheduling within the time the user asked for.
+func partialPlan(g *Graph, paths [][]int, ants int, err error) ([][]Move, error) {
+	if len(paths) == 0 || !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return nil, err
+	}
+	if len(paths) > heuristics.BoundedPaths {
+		sort.SliceStable(paths, func(i, j int) bool { return pathLess(paths[i], paths[j]) })
+		paths = paths[:heuristics.BoundedPaths]
+	}
+	explainLog.Printf("partial: scheduling on %d paths", len(paths))
+	return schedule(g, paths, ants), err
+}
+
+// solveBounded only looks at the first heuristics.BoundedPaths paths found
+// by the DFS, which keeps huge maps tractable at the cost of ignoring the
+// rest of the colony
+func solveBounded(ctx context.Context, g *Graph, start, end, ants int) ([][]Move, error) {
+	paths, err := g.FindPaths(ctx, start, end, heuristics.BoundedPaths)
+	if err != nil {
+		return partialPlan(g, paths, ants, err)
+	}
+	explainLog.Printf("bounded: using the first %d paths", len(paths))
+	return schedule(g, paths, ants), nil
+}
+
+// solveAuto picks an algorithm from the size of the map: the exact
+// scheduler when its time-expanded network is small, the full DFS when the
+// number of paths is manageable and max-flow otherwise. Both thresholds
+// come from heuristics.
+func solveAuto(ctx context.Context, g *Graph, start, end, ants int) ([][]Move, error) {
+	shortest := g.distance(start, end)
+	if shortest < 0 {
+		return nil, ErrNoPath
+	}
+
+	rooms, tunnels := len(g.names), len(g.tunnels())
+	nodes := (2*rooms + 2*tunnels) * (shortest + ants)
+	explainLog.Printf("auto: %d rooms, %d tunnels, %d ants, shortest path %d, time-expanded size %d",
+		rooms, tunnels, ants, shortest, nodes)
+
+	if nodes <= heuristics.AutoExactNodes && closedSlots == nil && zoneLimits == nil {
+		explainLog.Printf("auto: time-expanded size within %d, using exact", heuristics.AutoExactNodes)
+		return g.ExactSchedule(ctx, start, end, ants)
+	}
+
+	estimated, err := g.FindPaths(ctx, start, end, heuristics.AutoPaths)
+	if err != nil {
+		return partialPlan(g, estimated, ants, err)
+	}
+	estimate := len(estimated)
+	explainLog.Printf("auto: estimated path count %d", estimate)
+	if estimate < heuristics.AutoPaths {
+		explainLog.Printf("auto: fewer than %d paths, using dfs", heuristics.AutoPaths)
+		return solveDFS(ctx, g, start, end, ants)
+	}
+
+	explainLog.Printf("auto: at least %d paths, using flow", heuristics.AutoPaths)
+	return solveFlow(ctx, g, start, end, ants)
+}
@@ -0,0 +1,76 @@
+package main
+
+import "errors"
+
+// spawnRate limits how many ants may leave the start room in one turn
+// (--spawn-rate), modelling a narrow nest exit. Zero means no limit.
+var spawnRate int
+
+var errSpawnRate = errors.New("the CBS solver does not support a spawn rate")
+
+// spawnSlot marks the departures from start during turn as used up. It is
+// separate from the room slot of start, which would also stop ants from
+// waiting there.
+func spawnSlot(start, turn int) slot {
+	return slot{start, -2, turn}
+}
+
+// spawnCounter counts the ants leaving the start room per turn and
+// reserves the spawn slot of turns that reached the spawn rate
+type spawnCounter struct {
+	start      int
+	departures map[int]int
+}
+
+func newSpawnCounter(start int) *spawnCounter {
+	return &spawnCounter{start: start, departures: make(map[int]int)}
+}
+
+// leave records an ant leaving the start room on turn
+func (c *spawnCounter) leave(table reservationTable, turn int) {
+	c.departures[turn]++
+	if spawnRate > 0 && c.departures[turn] >= spawnRate {
+		table[spawnSlot(c.start, turn)] = true
+	}
+}
+
+// SpawnStats shows whether the spawn rate limited the plan. The plan
+// cannot be shorter than SpawnBound turns: one turn for every further group
+// of Rate ants, plus the shortest path for the last group.
+type SpawnStats struct {
+	Rate       int  `json:"rate"`
+	FullTurns  int  `json:"full_turns"` // turns in which Rate ants left the start room
+	SpawnBound int  `json:"spawn_bound"`
+	Binding    bool `json:"binding"` // the plan takes exactly SpawnBound turns
+}
+
+func (s *Solution) spawnStats(ants int) *SpawnStats {
+	if spawnRate <= 0 {
+		return nil
+	}
+	st := &SpawnStats{Rate: spawnRate}
+	left := make(map[int]bool)
+	for _, moves := range s.Turns {
+		departed := 0
+		for _, move := range moves {
+			if !left[move.Ant] {
+				left[move.Ant] = true
+				departed++
+			}
+		}
+		if departed >= spawnRate {
+			st.FullTurns++
+		}
+	}
+	if shortest := s.Graph.distance(s.Start, s.End); shortest > 0 {
+		st.SpawnBound = spawnBound(shortest, ants)
+		st.Binding = len(s.Turns) == st.SpawnBound
+	}
+	return st
+}
+
+// spawnBound is the fewest turns in which ants can leave start at
+// spawnRate per turn and walk a path of shortest tunnels
+func spawnBound(shortest, ants int) int {
+	return shortest + (ants+spawnRate-1)/spawnRate - 1
+}
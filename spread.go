@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Planning objectives (--objective): the fewest turns, or the lowest peak
+// of ant-turns in any room within a turn budget, for maps that model wear
+// or crowding rather than speed
+const (
+	objectiveTurns  = "turns"
+	objectiveSpread = "spread"
+)
+
+var objective = objectiveTurns
+
+// turnBudget is the number of turns a spread plan may take (--turn-budget);
+// zero allows as many as the fastest plan
+var turnBudget int
+
+// peakAntTurns returns the most ant-turns spent in a room other than start
+// and end, as ranked by Solution.dwell
+func peakAntTurns(g *Graph, start, end int, turns [][]Move) int {
+	dwell := (&Solution{Graph: g, Start: start, End: end, Turns: turns}).dwell()
+	if len(dwell) == 0 {
+		return 0
+	}
+	return dwell[0].AntTurns
+}
+
+// spreadPlan replans the fastest plan for the spread objective. Ants
+// reserve whole paths when they leave, so they never wait on the way and
+// the ant-turns of a room are the ants routed through it. The paths are
+// those of a bounded search (heuristics.AutoPaths) that fit the budget;
+// the smallest peak for which scheduleWithin still places every ant is
+// found by bisection, and the fastest plan is kept when no lower peak fits.
+// Both plans are reported on stderr.
+func spreadPlan(ctx context.Context, g *Graph, start, end, ants int, fastest [][]Move) [][]Move {
+	budget := turnBudget
+	if budget == 0 {
+		budget = len(fastest)
+	}
+	peak := peakAntTurns(g, start, end, fastest)
+	if ants == 0 || peak <= 1 {
+		fmt.Fprintf(os.Stderr, "spread: the fastest plan has a peak of %d ant-turns in a room in %d turns\n", peak, len(fastest))
+		return fastest
+	}
+
+	found, err := g.FindPaths(ctx, start, end, heuristics.AutoPaths)
+	if err != nil {
+		explainLog.Printf("spread: path search stopped: %v", err)
+	}
+	var paths [][]int
+	for _, path := range found {
+		if len(path)-1 <= budget {
+			paths = append(paths, path)
+		}
+	}
+
+	var best [][]Move
+	bestPeak := peak
+	for low, high := 1, peak-1; low <= high && len(paths) > 0; {
+		mid := (low + high) / 2
+		turns := scheduleWithin(paths, ants, budget, mid)
+		if turns != nil && validateSchedule(g, start, end, ants, turns) == nil {
+			best, bestPeak = turns, peakAntTurns(g, start, end, turns)
+			high = bestPeak - 1
+		} else {
+			low = mid + 1
+		}
+	}
+	if best == nil {
+		fmt.Fprintf(os.Stderr, "spread: no plan within %d turns lowers the peak of %d ant-turns in a room of the fastest plan (%d turns)\n",
+			budget, peak, len(fastest))
+		return fastest
+	}
+	fmt.Fprintf(os.Stderr, "spread: peak of %d ant-turns in a room in %d turns, against %d ant-turns in %d turns for the fastest plan\n",
+		bestPeak, len(best), peak, len(fastest))
+	return best
+}
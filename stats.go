@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxHistogramBuckets is the number of buckets of the arrival histogram
+const maxHistogramBuckets = 10
+
+// maxDwellRooms is the number of busiest rooms printed by --stats
+const maxDwellRooms = 10
+
+// Stats summarizes a solution
+type Stats struct {
+	Turns       int          `json:"turns"`
+	Paths       int          `json:"paths"`
+	AntsPerPath []int        `json:"ants_per_path"` // in the order of the paths, see Solution.paths
+	LongestPath int          `json:"longest_path"`  // tunnels on the longest path used
+	Arrival     ArrivalStats `json:"arrival"`
+	Dwell       []RoomDwell  `json:"dwell"`
+	Zones       []ZoneDwell  `json:"zones,omitempty"`
+	Exit        *ExitStats   `json:"exit,omitempty"`
+	Spawn       *SpawnStats  `json:"spawn,omitempty"`
+
+	// SolveTime is the wall-clock time taken to plan, when known. It is
+	// left out of JSON so documents for the same input stay identical.
+	SolveTime time.Duration `json:"-"`
+}
+
+// ArrivalStats describes the distribution of the turns in which ants reach
+// the end room. A long tail usually points at a bad choice of paths.
+type ArrivalStats struct {
+	Min       int      `json:"min"`
+	Max       int      `json:"max"`
+	Mean      float64  `json:"mean"`
+	Histogram []Bucket `json:"histogram"`
+}
+
+// Bucket counts the ants arriving between turns From and To, inclusive
+type Bucket struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	Ants int `json:"ants"`
+}
+
+// RoomDwell is the number of ant-turns spent in a room: every turn ends
+// with an ant in the room counts once. Rooms with the most ant-turns are
+// where ants pile up, and the first to look at when redesigning a map.
+type RoomDwell struct {
+	Room     string `json:"room"`
+	AntTurns int    `json:"ant_turns"`
+}
+
+// ZoneDwell sums the ant-turns of the rooms of a zone (##zone), start and
+// end left out as for RoomDwell. Peak is the most ants in the zone at the
+// end of a turn, first reached in PeakTurn, which never exceeds Capacity
+// when the zone has one.
+type ZoneDwell struct {
+	Zone     string `json:"zone"`
+	Capacity int    `json:"capacity,omitempty"`
+	AntTurns int    `json:"ant_turns"`
+	Peak     int    `json:"peak"`
+	PeakTurn int    `json:"peak_turn"`
+}
+
+func (s *Solution) stats() Stats {
+	var arrivals []int
+	for i, moves := range s.Turns {
+		for _, move := range moves {
+			if move.Room == s.End {
+				arrivals = append(arrivals, i+1)
+			}
+		}
+	}
+	st := Stats{Turns: len(s.Turns), AntsPerPath: []int{}, Arrival: arrivalStats(arrivals), Dwell: s.dwell(), Zones: s.zoneDwell(), Exit: s.exitStats(len(arrivals)), Spawn: s.spawnStats(len(arrivals))}
+	for _, path := range s.paths() {
+		st.Paths++
+		st.AntsPerPath = append(st.AntsPerPath, path.Ants)
+		st.LongestPath = max(st.LongestPath, len(path.Rooms)-1)
+	}
+	return st
+}
+
+// dwell ranks the rooms other than start and end by ant-turns spent there,
+// busiest first
+func (s *Solution) dwell() []RoomDwell {
+	ants := 0
+	for _, moves := range s.Turns {
+		for _, move := range moves {
+			ants = max(ants, move.Ant)
+		}
+	}
+	position := make([]int, ants+1)
+	for ant := range position {
+		position[ant] = s.Start
+	}
+
+	antTurns := make(map[int]int)
+	for _, moves := range s.Turns {
+		for _, move := range moves {
+			position[move.Ant] = move.Room
+		}
+		for _, room := range position[1:] {
+			if room != s.Start && room != s.End {
+				antTurns[room]++
+			}
+		}
+	}
+
+	dwell := make([]RoomDwell, 0, len(antTurns))
+	for room, n := range antTurns {
+		dwell = append(dwell, RoomDwell{Room: s.Graph.Name(room), AntTurns: n})
+	}
+	sort.Slice(dwell, func(i, j int) bool {
+		if dwell[i].AntTurns != dwell[j].AntTurns {
+			return dwell[i].AntTurns > dwell[j].AntTurns
+		}
+		return dwell[i].Room < dwell[j].Room
+	})
+	return dwell
+}
+
+// zoneDwell aggregates the occupancy of every zone, in the order of the map
+func (s *Solution) zoneDwell() []ZoneDwell {
+	if len(s.Graph.zones) == 0 {
+		return nil
+	}
+	zoneOf := make(map[int]int)
+	for i, zone := range s.Graph.zones {
+		for _, name := range zone.Rooms {
+			if room, ok := s.Graph.ID(name); ok && room != s.Start && room != s.End {
+				zoneOf[room] = i
+			}
+		}
+	}
+	zones := make([]ZoneDwell, len(s.Graph.zones))
+	for i, zone := range s.Graph.zones {
+		zones[i].Zone, zones[i].Capacity = zone.Name, zone.Capacity
+	}
+
+	position := make(map[int]int) // room of every ant that left start
+	for turn, moves := range s.Turns {
+		for _, move := range moves {
+			position[move.Ant] = move.Room
+		}
+		count := make([]int, len(zones))
+		for _, room := range position {
+			if i, ok := zoneOf[room]; ok {
+				count[i]++
+			}
+		}
+		for i, n := range count {
+			zones[i].AntTurns += n
+			if n > zones[i].Peak {
+				zones[i].Peak, zones[i].PeakTurn = n, turn+1
+			}
+		}
+	}
+	return zones
+}
+
+func arrivalStats(arrivals []int) ArrivalStats {
+	if len(arrivals) == 0 {
+		return ArrivalStats{}
+	}
+
+	stats := ArrivalStats{Min: arrivals[0], Max: arrivals[0]}
+	sum := 0
+	for _, turn := range arrivals {
+		stats.Min = min(stats.Min, turn)
+		stats.Max = max(stats.Max, turn)
+		sum += turn
+	}
+	stats.Mean = float64(sum) / float64(len(arrivals))
+
+	span := stats.Max - stats.Min + 1
+	width := (span + maxHistogramBuckets - 1) / maxHistogramBuckets
+	for from := stats.Min; from <= stats.Max; from += width {
+		stats.Histogram = append(stats.Histogram, Bucket{From: from, To: min(from+width-1, stats.Max)})
+	}
+	for _, turn := range arrivals {
+		stats.Histogram[(turn-stats.Min)/width].Ants++
+	}
+	return stats
+}
+
+func (st Stats) write(w io.Writer) {
+	fmt.Fprintln(w, "turns:", st.Turns)
+	if st.SolveTime > 0 {
+		fmt.Fprintln(w, "solve time:", st.SolveTime.Round(time.Microsecond))
+	}
+	ants := make([]string, len(st.AntsPerPath))
+	for i, n := range st.AntsPerPath {
+		ants[i] = strconv.Itoa(n)
+	}
+	fmt.Fprintf(w, "paths: %d, ants per path %s, longest %d tunnels\n", st.Paths, strings.Join(ants, "/"), st.LongestPath)
+	a := st.Arrival
+	fmt.Fprintf(w, "arrival: min %d, max %d, mean %.2f\n", a.Min, a.Max, a.Mean)
+	for _, b := range a.Histogram {
+		label := fmt.Sprint(b.From)
+		if b.To != b.From {
+			label = fmt.Sprintf("%d-%d", b.From, b.To)
+		}
+		fmt.Fprintf(w, "  %9s | %s %d\n", label, strings.Repeat("#", min(b.Ants, maxBarWidth)), b.Ants)
+	}
+	if e := st.Exit; e != nil {
+		binding := "not binding"
+		if e.Binding {
+			binding = "binding"
+		}
+		fmt.Fprintf(w, "exit: capacity %d, full in %d turns, at least %d turns (%s)\n", e.Capacity, e.FullTurns, e.ExitBound, binding)
+	}
+	if sp := st.Spawn; sp != nil {
+		binding := "not binding"
+		if sp.Binding {
+			binding = "binding"
+		}
+		fmt.Fprintf(w, "spawn: rate %d, full in %d turns, at least %d turns (%s)\n", sp.Rate, sp.FullTurns, sp.SpawnBound, binding)
+	}
+	if len(st.Dwell) > 0 {
+		fmt.Fprintln(w, "busiest rooms (ant-turns):")
+	}
+	for _, d := range st.Dwell[:min(len(st.Dwell), maxDwellRooms)] {
+		fmt.Fprintf(w, "  %9s | %d\n", d.Room, d.AntTurns)
+	}
+	if len(st.Zones) > 0 {
+		fmt.Fprintln(w, "zones (ant-turns, most ants at once):")
+	}
+	for _, z := range st.Zones {
+		capacity := ""
+		if z.Capacity > 0 {
+			capacity = fmt.Sprintf(" (capacity %d)", z.Capacity)
+		}
+		fmt.Fprintf(w, "  %9s | %d, %d in turn %d%s\n", z.Zone, z.AntTurns, z.Peak, z.PeakTurn, capacity)
+	}
+}
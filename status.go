@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// solverStatus describes what a running solve is doing. It is dumped to
+// stderr on SIGUSR1 so long solves are not a black box.
+type solverStatus struct {
+	mu        sync.Mutex
+	started   time.Time
+	phase     string
+	bestTurns int
+	stage     int // stage of the fallback chain being run, from 1
+	stages    int
+
+	paths atomic.Int64 // paths found by the DFS, updated on the hot path
+}
+
+var status = &solverStatus{started: time.Now()}
+
+func (s *solverStatus) setPhase(format string, args ...interface{}) {
+	s.mu.Lock()
+	s.phase = fmt.Sprintf(format, args...)
+	s.mu.Unlock()
+}
+
+// reset starts the status over for a new solve
+func (s *solverStatus) reset() {
+	s.mu.Lock()
+	s.started, s.phase, s.bestTurns, s.stage, s.stages = time.Now(), "", 0, 0, 0
+	s.mu.Unlock()
+	s.paths.Store(0)
+}
+
+// setStage records that stage of the stages of a chain is running
+func (s *solverStatus) setStage(stage, stages int) {
+	s.mu.Lock()
+	s.stage, s.stages = stage, stages
+	s.mu.Unlock()
+}
+
+// foundPlan records a complete plan, keeping the lowest turn count
+func (s *solverStatus) foundPlan(turns int) {
+	s.mu.Lock()
+	if s.bestTurns == 0 || turns < s.bestTurns {
+		s.bestTurns = turns
+	}
+	s.mu.Unlock()
+}
+
+func (s *solverStatus) dump(w io.Writer) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(w, "status: %s after %v\n", s.phase, time.Since(s.started).Round(time.Millisecond))
+	fmt.Fprintf(w, "status: %d paths found\n", s.paths.Load())
+	if s.bestTurns > 0 {
+		fmt.Fprintf(w, "status: best plan so far takes %d turns\n", s.bestTurns)
+	} else {
+		fmt.Fprintln(w, "status: no plan yet")
+	}
+	fmt.Fprintf(w, "status: %d MiB heap in use, %d MiB from the OS\n", mem.HeapAlloc>>20, mem.Sys>>20)
+}
+
+// statusReport is a snapshot of the status, as served at
+// /solve/{id}/status. Progress is a rough estimate between 0 and 1: the
+// share of the fallback chain already run, since nothing tells how long a
+// single solver still needs.
+type statusReport struct {
+	Phase     string  `json:"phase"`
+	ElapsedMS int64   `json:"elapsed_ms"`
+	Stage     int     `json:"stage,omitempty"`
+	Stages    int     `json:"stages,omitempty"`
+	Progress  float64 `json:"progress"`
+	Paths     int64   `json:"paths_found"`
+	BestTurns int     `json:"best_turns,omitempty"`
+}
+
+func (s *solverStatus) report() statusReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := statusReport{
+		Phase:     s.phase,
+		ElapsedMS: time.Since(s.started).Milliseconds(),
+		Stage:     s.stage,
+		Stages:    s.stages,
+		Paths:     s.paths.Load(),
+		BestTurns: s.bestTurns,
+	}
+	if s.stages > 0 {
+		report.Progress = float64(s.stage-1) / float64(s.stages)
+	}
+	return report
+}
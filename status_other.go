@@ -0,0 +1,6 @@
+//go:build !unix
+
+package main
+
+// There is no SIGUSR1 outside unix systems
+func dumpStatusOnSignal() {}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// dumpStatusOnSignal prints the solver status every time SIGUSR1 arrives
+func dumpStatusOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	go func() {
+		for range signals {
+			status.dump(os.Stderr)
+		}
+	}()
+}
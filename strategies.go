@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/pathfinder"
+)
+
+// The built-in path-selection strategies of --strategy
+func init() {
+	pathfinder.Register("shortest", graphStrategy(func(g *Graph, ctx context.Context, start, end, ants int) ([][]int, error) {
+		return [][]int{g.shortestPath(start, end)}, nil
+	}))
+	pathfinder.Register("disjoint-flow", graphStrategy((*Graph).disjointPaths))
+	pathfinder.Register("heuristic", graphStrategy(func(g *Graph, ctx context.Context, start, end, ants int) ([][]int, error) {
+		return g.FindPaths(ctx, start, end, 0)
+	}))
+}
+
+// graphStrategy turns a path search on the room graph into a strategy.
+// It is a pathfinder.ContextStrategy, so --timeout stops the search and
+// keeps the paths found so far.
+type graphStrategy func(g *Graph, ctx context.Context, start, end, ants int) ([][]int, error)
+
+func (find graphStrategy) FindPaths(c *colony.Colony) ([]pathfinder.Path, error) {
+	return find.FindPathsCtx(context.Background(), c)
+}
+
+func (find graphStrategy) FindPathsCtx(ctx context.Context, c *colony.Colony) ([]pathfinder.Path, error) {
+	g := graphFromColony(c)
+	start, _ := g.ID(c.Start)
+	end, _ := g.ID(c.End)
+	paths, err := find(g, ctx, start, end, c.Ants)
+	named := make([]pathfinder.Path, 0, len(paths))
+	for _, path := range paths {
+		if path != nil {
+			named = append(named, g.PathNames(path))
+		}
+	}
+	return named, err
+}
+
+// shortestPath returns a path with the fewest tunnels, or nil
+func (g *Graph) shortestPath(start, end int) []int {
+	parent := make([]int, len(g.names))
+	for i := range parent {
+		parent[i] = -1
+	}
+	parent[start] = start
+	queue := []int{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == end {
+			var path []int
+			for room := end; room != start; room = parent[room] {
+				path = append(path, room)
+			}
+			path = append(path, start)
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		for _, neighbor := range g.vertices[current] {
+			if parent[neighbor] < 0 && g.leads(current, neighbor) {
+				parent[neighbor] = current
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+	return nil
+}
+
+// solveStrategy schedules the ants of c on the paths chosen by the named
+// strategy (--strategy). The paths come from outside the solver, so each
+// one is checked to run from start to end along tunnels.
+func solveStrategy(c *colony.Colony, name string) (*Solution, error) {
+	strategy, err := pathfinder.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	graph := graphFromColony(c)
+	start, _ := graph.ID(c.Start)
+	end, _ := graph.ID(c.End)
+	stage := "strategy " + name
+	if start == end {
+		return &Solution{Graph: graph, Start: start, End: end, Stage: stage}, nil
+	}
+
+	if err := applyRules(graph, start, end, c.Ants); err != nil {
+		return nil, err
+	}
+	zoneLimits = newZoneLimit(graph, start, end)
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if solveTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, solveTimeout)
+	}
+	defer cancel()
+
+	status.setPhase("finding paths with %s", stage)
+	named, err := pathfinder.FindPathsCtx(ctx, strategy, c)
+	switch {
+	case err != nil && ctx.Err() != nil && len(named) > 0:
+		explainLog.Printf("%s: stopped after %v, using the %d paths found so far", stage, solveTimeout, len(named))
+	case err != nil:
+		return nil, fmt.Errorf("%s: %w", stage, err)
+	}
+	ps := &PathSet{Graph: graph, Start: start, End: end}
+	for i, path := range named {
+		ids, err := pathIDs(graph, path, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("%s: path %d: %w", stage, i+1, err)
+		}
+		ps.Paths = append(ps.Paths, ids)
+	}
+	if len(ps.Paths) == 0 {
+		return nil, ErrNoPath
+	}
+
+	solution := AssignAnts(ps, c.Ants)
+	solution.Stage = stage
+	if err := validateSchedule(graph, start, end, c.Ants, solution.Turns); err != nil {
+		return nil, fmt.Errorf("%s: %w", stage, err)
+	}
+	return solution, nil
+}
+
+// pathIDs resolves the rooms of a path and checks that it leads from
+// start to end through tunnels without visiting a room twice
+func pathIDs(g *Graph, path pathfinder.Path, start, end int) ([]int, error) {
+	ids := make([]int, len(path))
+	seen := make(map[int]bool, len(path))
+	for i, name := range path {
+		id, ok := g.ID(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown room %s", name)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("room %s is visited twice", name)
+		}
+		seen[id] = true
+		if i > 0 && !g.hasEdge(ids[i-1], id) {
+			return nil, fmt.Errorf("no tunnel between %s and %s", path[i-1], name)
+		}
+		if i > 0 && !g.leads(ids[i-1], id) {
+			return nil, fmt.Errorf("tunnel %s->%s is taken backwards", name, path[i-1])
+		}
+		ids[i] = id
+	}
+	if len(ids) < 2 || ids[0] != start || ids[len(ids)-1] != end {
+		return nil, fmt.Errorf("does not lead from %s to %s", g.Name(start), g.Name(end))
+	}
+	return ids, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+	"lem2/pkg/simulator"
+)
+
+// stressPaths caps the paths handed to the perturbed scheduler, which
+// keeps huge maps from spending every trial enumerating paths
+const stressPaths = 200
+
+// runStress implements "lem-in stress <map>": a stress test of the
+// collision rules. Every trial schedules the ants with random choices the
+// real schedulers never make: each ant takes a random path, departs up to
+// --jitter turns later than it could, and ants are scheduled in random
+// order. The reservation table must still keep them apart, and both
+// validateSchedule and the simulator package must accept every plan and
+// agree with each other. The exit status is 1 when a trial fails; its
+// seed reproduces it.
+func runStress(args []string) int {
+	flags := flag.NewFlagSet("stress", flag.ExitOnError)
+	trials := flags.Int("trials", 100, "number of perturbed schedules to check")
+	seed := flags.Int64("seed", 1, "seed of the first trial; trial i uses seed+i")
+	jitter := flags.Int("jitter", 3, "most turns an ant may wait beyond its earliest departure")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *trials <= 0 || *jitter < 0 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in stress [--trials n] [--seed s] [--jitter turns] <map>")
+		return 2
+	}
+
+	c, err := parser.ParseInput(flags.Arg(0), parser.Strict01Edu)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	g := graphFromColony(c)
+	start, _ := g.ID(c.Start)
+	end, _ := g.ID(c.End)
+	if start == end {
+		fmt.Println("OK: start is end, no moves to check")
+		return 0
+	}
+	paths, err := g.FindPaths(context.Background(), start, end, stressPaths)
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		return 1
+	}
+
+	shortest, longest := 0, 0
+	for i := 0; i < *trials; i++ {
+		trialSeed := *seed + int64(i)
+		turns := perturbedSchedule(rand.New(rand.NewSource(trialSeed)), paths, c.Ants, *jitter)
+		if err := checkStressTrial(c, g, start, end, turns); err != nil {
+			fmt.Printf("FAIL: trial %d (--seed %d --trials 1 --jitter %d): %v\n", i+1, trialSeed, *jitter, err)
+			recordFailure(g, start, end, c.Ants, "stress", turns, err)
+			return 1
+		}
+		if i == 0 || len(turns) < shortest {
+			shortest = len(turns)
+		}
+		longest = max(longest, len(turns))
+	}
+	fmt.Printf("OK: %d trials on %d paths, %d to %d turns\n", *trials, len(paths), shortest, longest)
+	return 0
+}
+
+// checkStressTrial runs both validators on a plan; a plan only one of them
+// rejects is a bug in the other
+func checkStressTrial(c *colony.Colony, g *Graph, start, end int, turns [][]Move) error {
+	errSchedule := validateSchedule(g, start, end, c.Ants, turns)
+
+	named := make([][]simulator.Move, len(turns))
+	for i, moves := range turns {
+		for _, move := range moves {
+			named[i] = append(named[i], simulator.Move{Ant: move.Ant, Room: g.Name(move.Room)})
+		}
+	}
+	sim := simulator.New(c, named)
+	for {
+		if _, ok := sim.Step(); !ok {
+			break
+		}
+	}
+	errSim := sim.Err()
+
+	switch {
+	case errSchedule != nil && errSim != nil:
+		return fmt.Errorf("%v (simulator: %v)", errSchedule, errSim)
+	case errSchedule != nil:
+		return fmt.Errorf("validateSchedule rejects a plan the simulator accepts: %v", errSchedule)
+	case errSim != nil:
+		return fmt.Errorf("the simulator rejects a plan validateSchedule accepts: %v", errSim)
+	}
+	if state := sim.State(); state.AtEnd != c.Ants {
+		return errors.New("validateSchedule accepts a plan that leaves ants short of the end")
+	}
+	return nil
+}
+
+// perturbedSchedule is ScheduleAnts with random choices: ants are placed
+// in random order, on random paths, and may wait up to jitter turns past
+// their earliest departure
+func perturbedSchedule(r *rand.Rand, paths [][]int, ants, jitter int) [][]Move {
+	table := closedSlots.clone()
+	exits := newExitCounter(paths[0][len(paths[0])-1])
+	spawns := newSpawnCounter(paths[0][0])
+	zones := newZoneCounter()
+	var turns [][]Move
+	for _, ant := range r.Perm(ants) {
+		path := paths[r.Intn(len(paths))]
+		depart := table.earliest(path, table.earliest(path, 0)+r.Intn(jitter+1))
+		table.reserve(path, depart)
+		exits.arrive(table, depart+len(path)-1)
+		spawns.leave(table, depart+1)
+
+		for pos := 1; pos < len(path); pos++ {
+			turn := depart + pos
+			for len(turns) < turn {
+				turns = append(turns, nil)
+			}
+			turns[turn-1] = append(turns[turn-1], Move{Ant: ant + 1, Room: path[pos]})
+			zones.occupy(table, path[pos], turn)
+		}
+	}
+	return turns
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"lem2/pkg/parser"
+)
+
+func TestPerturbedSchedules(t *testing.T) {
+	for _, file := range []string{"small.map", "medium.map"} {
+		c, err := parser.ParseInput("testdata/bench/"+file, parser.Strict01Edu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := graphFromColony(c)
+		start, _ := g.ID(c.Start)
+		end, _ := g.ID(c.End)
+		paths, err := g.FindPaths(context.Background(), start, end, stressPaths)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for seed := int64(1); seed <= 20; seed++ {
+			turns := perturbedSchedule(rand.New(rand.NewSource(seed)), paths, c.Ants, 5)
+			if err := checkStressTrial(c, g, start, end, turns); err != nil {
+				t.Errorf("%s, seed %d: %v", file, seed, err)
+			}
+		}
+	}
+}
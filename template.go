@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// Data passed to the output templates
+type (
+	moveData struct {
+		Ant  int
+		Room string
+		Turn int
+	}
+	turnData struct {
+		Turn  int
+		Moves []moveData
+	}
+	summaryData struct {
+		Ants  int
+		Turns int
+		Stats Stats
+	}
+)
+
+// loadTemplate parses a --template file. The file may define templates
+// named "turn" (run once per turn), "move" (run once per move, used when
+// there is no "turn" template) and "summary" (run once at the end); a file
+// without any of them is used as the "turn" template.
+func loadTemplate(file string) (*template.Template, error) {
+	text, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New("turn").Parse(string(text))
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.Lookup("move") == nil && tmpl.Lookup("summary") == nil && blank(tmpl.Lookup("turn")) {
+		return nil, fmt.Errorf("%s defines no turn, move or summary template", file)
+	}
+	return tmpl, nil
+}
+
+// blank reports whether a template only holds whitespace, which is what is
+// left of a file made of {{define}} blocks
+func blank(t *template.Template) bool {
+	return t == nil || t.Tree == nil || strings.TrimSpace(t.Tree.Root.String()) == ""
+}
+
+// writeTemplate renders the solution through the user's templates instead
+// of the standard move lines
+func writeTemplate(w io.Writer, tmpl *template.Template, ants int, s *Solution) error {
+	out := bufio.NewWriter(w)
+	if err := executeTemplate(out, tmpl, ants, s); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+func executeTemplate(w io.Writer, tmpl *template.Template, ants int, s *Solution) error {
+	turnTmpl := tmpl.Lookup("turn")
+	if blank(turnTmpl) {
+		turnTmpl = nil
+	}
+	moveTmpl := tmpl.Lookup("move")
+
+	names := s.roomNames()
+	for i, moves := range s.Turns {
+		turn := turnData{Turn: i + 1}
+		for j, move := range moves {
+			turn.Moves = append(turn.Moves, moveData{Ant: antNumber(move.Ant), Room: names[i][j], Turn: i + 1})
+		}
+
+		switch {
+		case turnTmpl != nil:
+			if err := turnTmpl.Execute(w, turn); err != nil {
+				return err
+			}
+		case moveTmpl != nil:
+			for _, move := range turn.Moves {
+				if err := moveTmpl.Execute(w, move); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if summary := tmpl.Lookup("summary"); summary != nil {
+		return summary.Execute(w, summaryData{Ants: ants, Turns: len(s.Turns), Stats: s.stats()})
+	}
+	return nil
+}
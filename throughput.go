@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxBarWidth is the widest bar drawn by the throughput chart
+const maxBarWidth = 50
+
+// turnThroughput counts the moves of a single turn
+type turnThroughput struct {
+	Moved   int `json:"moved"`
+	Arrived int `json:"arrived"`
+}
+
+// throughput counts, per turn, how many ants moved and how many of them
+// reached the end room
+func (s *Solution) throughput() []turnThroughput {
+	result := make([]turnThroughput, len(s.Turns))
+	for i, moves := range s.Turns {
+		result[i].Moved = len(moves)
+		for _, move := range moves {
+			if move.Room == s.End {
+				result[i].Arrived++
+			}
+		}
+	}
+	return result
+}
+
+// writeThroughput draws one bar per turn: '#' for ants arriving at the end
+// and '=' for the other moves, so turns where the schedule stalls stand out
+func (s *Solution) writeThroughput(w io.Writer) {
+	turns := s.throughput()
+	widest := 0
+	for _, t := range turns {
+		if t.Moved > widest {
+			widest = t.Moved
+		}
+	}
+	scale := func(n int) int {
+		if widest <= maxBarWidth {
+			return n
+		}
+		return (n*maxBarWidth + widest - 1) / widest
+	}
+
+	for i, t := range turns {
+		arrived := scale(t.Arrived)
+		bar := strings.Repeat("#", arrived) + strings.Repeat("=", scale(t.Moved)-arrived)
+		fmt.Fprintf(w, "%4d | %-*s %d moved, %d arrived\n", i+1, scale(widest), bar, t.Moved, t.Arrived)
+	}
+}
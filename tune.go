@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+)
+
+// tunedSolvers are the solvers whose plans depend on the heuristics
+var tunedSolvers = []string{"auto", "bounded"}
+
+// tuneValues are the values grid search tries for every number of the
+// profile; random search draws from the range between the first and the
+// last one
+var tuneValues = struct{ autoExactNodes, autoPaths, boundedPaths []int }{
+	autoExactNodes: []int{0, maxExactNodes / 100, maxExactNodes / 10, maxExactNodes},
+	autoPaths:      []int{10, 100, 1000, 10000},
+	boundedPaths:   []int{1, 10, 50, 200},
+}
+
+// corpusMap is a map of the tuning corpus
+type corpusMap struct {
+	name string
+	c    *colony.Colony
+}
+
+// runTune implements "lem-in tune --corpus dir": it solves every map of
+// the corpus with the solvers that depend on the heuristics, once per
+// candidate profile, and writes the profile with the fewest turns in
+// total to a file that --heuristics loads. The defaults are always the
+// first candidate and a candidate must do strictly better to replace the
+// best one, so the result never takes more turns than the defaults.
+func runTune(args []string) int {
+	flags := flag.NewFlagSet("tune", flag.ExitOnError)
+	dir := flags.String("corpus", "", "directory of .map files to tune on")
+	search := flags.String("search", "grid", "search strategy: grid or random")
+	trials := flags.Int("trials", 50, "profiles tried by random search")
+	seed := flags.Int64("seed", 1, "seed of random search")
+	out := flags.String("out", "heuristics.json", "file the best profile is written to")
+	flags.Parse(args)
+	if *dir == "" || flags.NArg() != 0 || *trials <= 0 || (*search != "grid" && *search != "random") {
+		fmt.Fprintln(os.Stderr, "usage: lem-in tune --corpus dir [--search grid|random] [--trials n] [--seed s] [--out file]")
+		return 2
+	}
+
+	corpus, err := loadCorpus(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+
+	candidates := gridProfiles()
+	if *search == "random" {
+		candidates = randomProfiles(rand.New(rand.NewSource(*seed)), *trials)
+	}
+	candidates = append([]heuristicProfile{defaultHeuristics}, candidates...)
+
+	defer func(saved heuristicProfile) { heuristics = saved }(heuristics)
+	best, bestTurns, defaultTurns := defaultHeuristics, 0, 0
+	for i, candidate := range candidates {
+		heuristics = candidate
+		turns, err := corpusTurns(corpus)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: %+v: %v\n", candidate, err)
+			return 1
+		}
+		if i == 0 {
+			best, bestTurns, defaultTurns = candidate, turns, turns
+			fmt.Printf("defaults %+v: %d turns\n", candidate, turns)
+			continue
+		}
+		if turns < bestTurns {
+			best, bestTurns = candidate, turns
+			fmt.Printf("candidate %d/%d %+v: %d turns\n", i, len(candidates)-1, candidate, turns)
+		}
+	}
+
+	if err := best.save(*out); err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	fmt.Printf("best of %d profiles on %d maps: %d turns (defaults %d), written to %s\n",
+		len(candidates), len(corpus), bestTurns, defaultTurns, *out)
+	return 0
+}
+
+// loadCorpus parses the .map files of dir, in order of name
+func loadCorpus(dir string) ([]corpusMap, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.map"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .map files in %s", dir)
+	}
+	corpus := make([]corpusMap, 0, len(files))
+	for _, file := range files {
+		c, err := parser.ParseInput(file, parser.Strict01Edu)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		corpus = append(corpus, corpusMap{name: filepath.Base(file), c: c})
+	}
+	return corpus, nil
+}
+
+// corpusTurns is the total number of turns of the tuned solvers on every
+// map of the corpus with the heuristics in use
+func corpusTurns(corpus []corpusMap) (int, error) {
+	total := 0
+	for _, m := range corpus {
+		for _, solver := range tunedSolvers {
+			solution, err := solveColony(m.c, []chainStage{{name: solver}})
+			if err != nil {
+				return 0, fmt.Errorf("%s/%s: %w", m.name, solver, err)
+			}
+			total += len(solution.Turns)
+		}
+	}
+	return total, nil
+}
+
+// gridProfiles returns every combination of tuneValues
+func gridProfiles() []heuristicProfile {
+	var profiles []heuristicProfile
+	for _, exactNodes := range tuneValues.autoExactNodes {
+		for _, autoPaths := range tuneValues.autoPaths {
+			for _, boundedPaths := range tuneValues.boundedPaths {
+				profiles = append(profiles, heuristicProfile{
+					AutoExactNodes: exactNodes,
+					AutoPaths:      autoPaths,
+					BoundedPaths:   boundedPaths,
+				})
+			}
+		}
+	}
+	return profiles
+}
+
+// randomProfiles draws n profiles within the ranges of tuneValues. Values
+// are spread evenly on a log scale, so small numbers, where a change
+// matters most, are tried as often as large ones.
+func randomProfiles(r *rand.Rand, n int) []heuristicProfile {
+	draw := func(values []int) int {
+		lo, hi := math.Log(float64(values[0]+1)), math.Log(float64(values[len(values)-1]+1))
+		return int(math.Round(math.Exp(lo+r.Float64()*(hi-lo)))) - 1
+	}
+	profiles := make([]heuristicProfile, n)
+	for i := range profiles {
+		profiles[i] = heuristicProfile{
+			AutoExactNodes: draw(tuneValues.autoExactNodes),
+			AutoPaths:      max(draw(tuneValues.autoPaths), 1),
+			BoundedPaths:   max(draw(tuneValues.boundedPaths), 1),
+		}
+	}
+	return profiles
+}
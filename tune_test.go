@@ -0,0 +1,66 @@
+package main
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTuneWritesLoadableProfile(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"small.map", "medium.map"} {
+		data, err := os.ReadFile("testdata/bench/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(dir, "heuristics.json")
+	if code := runTune([]string{"--corpus", dir, "--search", "random", "--trials", "5", "--out", out}); code != 0 {
+		t.Fatalf("tune exited with %d", code)
+	}
+	if heuristics != defaultHeuristics {
+		t.Errorf("tune left the heuristics at %+v", heuristics)
+	}
+
+	profile, err := loadHeuristics(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corpus, err := loadCorpus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults, err := corpusTurns(corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heuristics = profile
+	defer func() { heuristics = defaultHeuristics }()
+	tuned, err := corpusTurns(corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tuned > defaults {
+		t.Errorf("tuned profile %+v takes %d turns, defaults %d", profile, tuned, defaults)
+	}
+}
+
+func TestRandomProfiles(t *testing.T) {
+	first := randomProfiles(rand.New(rand.NewSource(3)), 20)
+	if again := randomProfiles(rand.New(rand.NewSource(3)), 20); !reflect.DeepEqual(first, again) {
+		t.Error("the same seed drew different profiles")
+	}
+	for _, profile := range first {
+		if err := profile.check(); err != nil {
+			t.Error(err)
+		}
+		if profile.BoundedPaths > tuneValues.boundedPaths[len(tuneValues.boundedPaths)-1] {
+			t.Errorf("bounded_paths %d is out of range", profile.BoundedPaths)
+		}
+	}
+}
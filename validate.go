@@ -0,0 +1,89 @@
+package main
+
+import "fmt"
+
+// validateSchedule replays a schedule and checks that ants only use existing
+// tunnels, move at most once per turn, never share a room other than start
+// and end or a tunnel within a turn, that no more ants than the exit
+// capacity enter the end and no more than the spawn rate leave the start
+// per turn, that no ant is in a room closed by the turn rules, that no
+// zone holds more ants than its capacity, and that every ant reaches the
+// end.
+func validateSchedule(g *Graph, start, end, ants int, turns [][]Move) error {
+	position := make([]int, ants+1)
+	for ant := 1; ant <= ants; ant++ {
+		position[ant] = start
+	}
+
+	for i, moves := range turns {
+		moved := make(map[int]bool)
+		used := make(map[slot]bool)
+		exited, departed := 0, 0
+		for _, move := range moves {
+			if move.Ant < 1 || move.Ant > ants {
+				return fmt.Errorf("turn %d: unknown ant %d", i+1, move.Ant)
+			}
+			if moved[move.Ant] {
+				return fmt.Errorf("turn %d: ant %d moves twice", i+1, move.Ant)
+			}
+			from := position[move.Ant]
+			if from == end {
+				return fmt.Errorf("turn %d: ant %d moves after reaching the end", i+1, move.Ant)
+			}
+			if from == start {
+				if departed++; spawnRate > 0 && departed > spawnRate {
+					return fmt.Errorf("turn %d: more than %d ants leave the start", i+1, spawnRate)
+				}
+			}
+			if !g.hasEdge(from, move.Room) {
+				return fmt.Errorf("turn %d: no tunnel from %s to %s for ant %d", i+1, g.Name(from), g.Name(move.Room), move.Ant)
+			}
+			if !g.leads(from, move.Room) {
+				return fmt.Errorf("turn %d: tunnel %s->%s is taken backwards by ant %d", i+1, g.Name(move.Room), g.Name(from), move.Ant)
+			}
+			tunnel := tunnelSlot(from, move.Room, 0)
+			if used[tunnel] {
+				return fmt.Errorf("turn %d: tunnel %s-%s used twice", i+1, g.Name(from), g.Name(move.Room))
+			}
+			used[tunnel] = true
+			if move.Room == end {
+				if closedSlots[roomSlot(end, i+1)] {
+					return fmt.Errorf("turn %d: ant %d enters the end while it is closed", i+1, move.Ant)
+				}
+				if exited++; exitCapacity > 0 && exited > exitCapacity {
+					return fmt.Errorf("turn %d: more than %d ants enter the end", i+1, exitCapacity)
+				}
+			}
+			moved[move.Ant] = true
+			position[move.Ant] = move.Room
+		}
+
+		occupied := make(map[int]int)
+		inZone := make(map[int]int)
+		for ant := 1; ant <= ants; ant++ {
+			room := position[ant]
+			if room == start || room == end {
+				continue
+			}
+			if closedSlots[roomSlot(room, i+1)] {
+				return fmt.Errorf("turn %d: ant %d is in closed room %s", i+1, ant, g.Name(room))
+			}
+			if other, ok := occupied[room]; ok {
+				return fmt.Errorf("turn %d: ants %d and %d share room %s", i+1, other, ant, g.Name(room))
+			}
+			occupied[room] = ant
+			if zone, ok := zoneLimits.of(room); ok {
+				if inZone[zone]++; inZone[zone] > zoneLimits.capacity[zone] {
+					return fmt.Errorf("turn %d: zone %s holds more than %d ants", i+1, zoneLimits.names[zone], zoneLimits.capacity[zone])
+				}
+			}
+		}
+	}
+
+	for ant := 1; ant <= ants; ant++ {
+		if position[ant] != end {
+			return fmt.Errorf("ant %d never reaches the end", ant)
+		}
+	}
+	return nil
+}
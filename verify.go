@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"lem2/pkg/colony"
+	"lem2/pkg/parser"
+	"lem2/pkg/verifier"
+)
+
+// runVerify implements "lem-in verify <map> <output> [<map> <output>...]":
+// it checks the moves of every output, with or without the echoed map,
+// against the rules of its map. Pairs are checked in parallel by --jobs
+// workers, and --report prints one entry per pair as JSON or as a JUnit
+// XML report for CI pipelines. The exit status is 1 when a rule is broken
+// or a pair cannot be checked.
+func runVerify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	profileName := flags.String("profile", "strict", "map format profile: "+strings.Join(parser.ProfileNames(), ", "))
+	jobs := flags.Int("jobs", runtime.NumCPU(), "number of pairs checked at once")
+	report := flags.String("report", "", "print a report of every pair instead of OK and FAIL lines: json or junit")
+	flags.Parse(args)
+	if flags.NArg() == 0 || flags.NArg()%2 != 0 || *jobs <= 0 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in verify [--profile name] [--jobs n] [--report json|junit] <map> <output> [<map> <output>...]")
+		return 2
+	}
+	if *report != "" && *report != "json" && *report != "junit" {
+		fmt.Fprintf(os.Stderr, "ERROR: unknown report %q (available: json, junit)\n", *report)
+		return 2
+	}
+	profile, err := parser.LookupProfile(*profileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 2
+	}
+
+	if flags.NArg() == 2 && *report == "" {
+		return verifyOne(flags.Arg(0), flags.Arg(1), profile)
+	}
+
+	results := verifyPairs(flags.Args(), profile, *jobs)
+	switch *report {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(results)
+	case "junit":
+		err = writeJUnit(os.Stdout, results)
+	default:
+		for _, result := range results {
+			if result.Status == verifyOK {
+				fmt.Printf("OK %s %s\n", result.Map, result.Output)
+			} else {
+				fmt.Printf("FAIL %s %s: %s\n", result.Map, result.Output, result.Error)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	for _, result := range results {
+		if result.Status != verifyOK {
+			return 1
+		}
+	}
+	return 0
+}
+
+// verifyOne checks a single pair and prints OK or FAIL
+func verifyOne(mapFile, outputFile string, profile parser.Profile) int {
+	c, err := parser.ParseInput(mapFile, profile)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	output, err := parser.ReadFile(outputFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	if err := verifyOutput(c, output); err != nil {
+		fmt.Println("FAIL:", err)
+		return 1
+	}
+	fmt.Println("OK")
+	return 0
+}
+
+// verifyOutput checks the lines of an output file against the colony
+func verifyOutput(c *colony.Colony, output []string) error {
+	moves := verifier.StripEcho(c, output)
+	err := verifier.Verify(c, moves)
+	// Point at the line of the output file rather than of the moves
+	var verr *verifier.Error
+	if errors.As(err, &verr) && verr.Line > 0 {
+		verr.Line += len(output) - len(moves)
+	}
+	return err
+}
+
+// Statuses of a verified pair: the output keeps the rules, breaks one, or
+// the pair could not be checked because a file is missing or the map is
+// invalid
+const (
+	verifyOK    = "ok"
+	verifyFail  = "fail"
+	verifyError = "error"
+)
+
+// verifyResult is the report entry of one pair. Line is the line of the
+// output file with the broken rule, when there is one.
+type verifyResult struct {
+	Map    string  `json:"map"`
+	Output string  `json:"output"`
+	Status string  `json:"status"`
+	Error  string  `json:"error,omitempty"`
+	Line   int     `json:"line,omitempty"`
+	Time   float64 `json:"seconds"`
+}
+
+// verifyPairs checks the map and output pairs of args with the given
+// number of workers and returns the results in the order of args
+func verifyPairs(args []string, profile parser.Profile, jobs int) []verifyResult {
+	results := make([]verifyResult, len(args)/2)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(jobs, len(results)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = verifyPair(args[2*i], args[2*i+1], profile)
+			}
+		}()
+	}
+	for i := range results {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// verifyPair checks one pair; the deferred timing needs the named result
+func verifyPair(mapFile, outputFile string, profile parser.Profile) (result verifyResult) {
+	started := time.Now()
+	result = verifyResult{Map: mapFile, Output: outputFile, Status: verifyOK}
+	defer func() { result.Time = time.Since(started).Seconds() }()
+
+	c, err := parser.ParseInput(mapFile, profile)
+	if err != nil {
+		result.Status, result.Error = verifyError, err.Error()
+		return result
+	}
+	output, err := parser.ReadFile(outputFile)
+	if err != nil {
+		result.Status, result.Error = verifyError, err.Error()
+		return result
+	}
+	if err := verifyOutput(c, output); err != nil {
+		result.Status, result.Error = verifyFail, err.Error()
+		var verr *verifier.Error
+		if errors.As(err, &verr) {
+			result.Line = verr.Line
+		}
+	}
+	return result
+}
+
+// JUnit XML report: one test case per pair, a broken rule is a failure
+// and a pair that could not be checked an error
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, results []verifyResult) error {
+	suite := junitSuite{Name: "lem-in verify", Tests: len(results)}
+	total := 0.0
+	for _, result := range results {
+		tc := junitCase{Name: result.Output, ClassName: result.Map, Time: fmt.Sprintf("%.3f", result.Time)}
+		problem := &junitProblem{Message: result.Error, Text: result.Error}
+		switch result.Status {
+		case verifyFail:
+			tc.Failure = problem
+			suite.Failures++
+		case verifyError:
+			tc.Error = problem
+			suite.Errors++
+		}
+		total += result.Time
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lem2/pkg/parser"
+)
+
+// TestVerifyPairs checks a good, a bad and a missing output in parallel
+// and reads the JUnit report back
+func TestVerifyPairs(t *testing.T) {
+	c, err := parser.ParseInput("testdata/bench/small.map", parser.Strict01Edu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution, err := solveColony(c, []chainStage{{name: "dfs"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	var good bytes.Buffer
+	if err := writeSolution(&good, c, solution); err != nil {
+		t.Fatal(err)
+	}
+	goodFile, badFile := filepath.Join(dir, "good.out"), filepath.Join(dir, "bad.out")
+	os.WriteFile(goodFile, good.Bytes(), 0o644)
+	os.WriteFile(badFile, []byte("L1-nowhere\n"), 0o644)
+
+	const m = "testdata/bench/small.map"
+	results := verifyPairs([]string{m, goodFile, m, badFile, m, filepath.Join(dir, "missing.out")}, parser.Strict01Edu, 2)
+	want := []string{verifyOK, verifyFail, verifyError}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("pair %d: got %s (%s), want %s", i+1, result.Status, result.Error, want[i])
+		}
+		if result.Time <= 0 {
+			t.Errorf("pair %d: got time %v, want it measured", i+1, result.Time)
+		}
+	}
+	if results[1].Line != 1 {
+		t.Errorf("got the broken rule on line %d, want 1", results[1].Line)
+	}
+
+	var report bytes.Buffer
+	if err := writeJUnit(&report, results); err != nil {
+		t.Fatal(err)
+	}
+	var suites junitSuites
+	if err := xml.Unmarshal(report.Bytes(), &suites); err != nil {
+		t.Fatal(err)
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Errors != 1 || suite.Cases[1].Failure == nil {
+		t.Errorf("got %+v", suite)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"lem2/pkg/colony"
+)
+
+// maxVisualizeColumns caps the width of the grid drawn by --visualize, so
+// huge maps fail clearly instead of wrapping into noise
+const maxVisualizeColumns = 240
+
+// ANSI sequences used by the animation
+const (
+	ansiClear = "\x1b[H\x1b[2J"
+	ansiAnt   = "\x1b[1;33m"
+	ansiReset = "\x1b[0m"
+)
+
+// visualize replays the solution as a terminal animation (--visualize).
+// Rooms keep the order of their coordinates, but distinct X and Y values
+// are packed into consecutive columns and rows so sparse maps still fit.
+// Every room shows the ant inside it, and start and end how many ants they
+// hold; one frame is drawn per turn, delay apart. Maps with zones also get
+// the number of ants in every zone below the grid.
+func (s *Solution) visualize(w io.Writer, c *colony.Colony, ants int, delay time.Duration) error {
+	grid, err := newFrameGrid(s, c, ants)
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(w)
+	positions := s.positionIndex(ants)
+	for turn := 0; turn <= len(s.Turns); turn++ {
+		if turn > 0 {
+			time.Sleep(delay)
+		}
+		grid.draw(out, turn, positions.at(turn))
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// frameGrid draws the frames of --visualize and --scrub
+type frameGrid struct {
+	s             *Solution
+	c             *colony.Colony
+	ants          int
+	column, row   map[int]int // rank of every X and Y coordinate
+	columns, rows int
+	width         int // of a cell, including the space between rooms
+}
+
+func newFrameGrid(s *Solution, c *colony.Colony, ants int) (*frameGrid, error) {
+	g := &frameGrid{s: s, c: c, ants: ants}
+	g.column, g.columns = coordinateRanks(c, func(r *colony.Room) int { return r.X })
+	g.row, g.rows = coordinateRanks(c, func(r *colony.Room) int { return r.Y })
+
+	// Rooms hold an ant label or, for start and end, a count
+	digits := max(len(antLabel(ants)), len(strconv.Itoa(ants)))
+	for name := range c.Rooms {
+		g.width = max(g.width, len(name)+digits+2)
+	}
+	g.width++ // a space between rooms
+	if g.columns*g.width > maxVisualizeColumns {
+		return nil, fmt.Errorf("the map needs %d columns, more than the %d --visualize draws", g.columns*g.width, maxVisualizeColumns)
+	}
+	return g, nil
+}
+
+// draw clears the terminal and draws the rooms with the ants at position,
+// indexed by ant, after the given turn
+func (g *frameGrid) draw(out io.Writer, turn int, position []int) {
+	s := g.s
+
+	// What every room shows in this frame
+	content := make(map[string]string)
+	count := make(map[int]int)
+	inZone := make(map[string]int)
+	for ant := 1; ant <= g.ants; ant++ {
+		room := position[ant]
+		count[room]++
+		if room != s.Start && room != s.End {
+			content[s.Graph.Name(room)] = antLabel(ant)
+			inZone[g.c.ZoneOf(s.Graph.Name(room))]++
+		}
+	}
+	content[g.c.Start] = strconv.Itoa(count[s.Start])
+	content[g.c.End] = strconv.Itoa(count[s.End])
+
+	grid := make([][]string, g.rows)
+	for i := range grid {
+		grid[i] = make([]string, g.columns)
+	}
+	for name, room := range g.c.Rooms {
+		cell := name + "[" + content[name] + "]"
+		padding := strings.Repeat(" ", g.width-len(cell))
+		if content[name] != "" && content[name] != "0" {
+			cell = ansiAnt + cell + ansiReset
+		}
+		grid[g.row[room.Y]][g.column[room.X]] = cell + padding
+	}
+
+	fmt.Fprint(out, ansiClear)
+	fmt.Fprintf(out, "turn %d/%d\n\n", turn, len(s.Turns))
+	for _, cells := range grid {
+		for _, cell := range cells {
+			if cell == "" {
+				cell = strings.Repeat(" ", g.width)
+			}
+			fmt.Fprint(out, cell)
+		}
+		fmt.Fprintln(out)
+	}
+	if len(g.c.Zones) > 0 {
+		counts := make([]string, len(g.c.Zones))
+		for i, zone := range g.c.Zones {
+			counts[i] = fmt.Sprintf("%s %d", zone.Name, inZone[zone.Name])
+		}
+		fmt.Fprintf(out, "\nzones: %s\n", strings.Join(counts, ", "))
+	}
+}
+
+// coordinateRanks maps every distinct value of a coordinate to its rank
+func coordinateRanks(c *colony.Colony, coordinate func(*colony.Room) int) (map[int]int, int) {
+	seen := make(map[int]bool)
+	var values []int
+	for _, room := range c.Rooms {
+		if v := coordinate(room); !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	sort.Ints(values)
+	ranks := make(map[int]int, len(values))
+	for i, v := range values {
+		ranks[v] = i
+	}
+	return ranks, len(values)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"lem2/pkg/parser"
+)
+
+// watchResult is the JSON document written next to every solved map
+type watchResult struct {
+	Map   string        `json:"map"`
+	Ants  int           `json:"ants,omitempty"`
+	Turns [][]namedMove `json:"turns,omitempty"`
+	Flow  []tunnelFlow  `json:"flow,omitempty"`
+	Stats *Stats        `json:"stats,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+// runWatch implements "lem-in watch <dir>": it polls the directory for new
+// or changed .map files and solves each of them, writing <name>.out and
+// <name>.json next to the map.
+func runWatch(args []string) int {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	algo := flags.String("algo", "dfs", "scheduling algorithm: "+solverNames())
+	chain := flags.String("chain", "", "fallback chain of algorithms with time budgets")
+	interval := flags.Duration("interval", time.Second, "how often to look for changes")
+	flags.StringVar(&corpusDir, "corpus", "", "save maps on which a solver produces an invalid plan to this directory")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: lem-in watch [flags] <dir>")
+		return 2
+	}
+	dir := flags.Arg(0)
+
+	stages, err := solverStages(*algo, *chain)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+
+	// Maps are often saved again with only the number of ants changed
+	cache = newPlanCache()
+
+	log.Printf("watching %s for .map files", dir)
+	seen := make(map[string]time.Time)
+	for {
+		files, err := filepath.Glob(filepath.Join(dir, "*.map"))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ERROR:", err)
+			return 1
+		}
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				continue
+			}
+			if modified, ok := seen[file]; ok && modified.Equal(info.ModTime()) {
+				continue
+			}
+			seen[file] = info.ModTime()
+			watchSolve(file, stages)
+		}
+		time.Sleep(*interval)
+	}
+}
+
+// watchSolve solves a single map and writes the .out and .json files
+func watchSolve(file string, stages []chainStage) {
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+	result := watchResult{Map: file}
+	var out bytes.Buffer
+
+	started := time.Now()
+	var solution *Solution
+	c, err := parser.ParseInput(file, parser.Strict01Edu)
+	if err == nil {
+		result.Ants = c.Ants
+		solution, err = solveColony(c, stages)
+	}
+	if err == nil {
+		result.Turns = solution.namedTurns()
+		result.Flow = solution.flowDecomposition()
+		stats := solution.stats()
+		result.Stats = &stats
+		writeSolution(&out, c, solution)
+		log.Printf("%s: %d turns in %v", file, len(result.Turns), time.Since(started).Round(time.Millisecond))
+	} else {
+		fmt.Fprintln(&out, err)
+		result.Error = err.Error()
+		log.Printf("%s: %v", file, err)
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+	if err := os.WriteFile(base+".out", out.Bytes(), 0o644); err != nil {
+		log.Printf("%s: %v", file, err)
+	}
+	if err := os.WriteFile(base+".json", append(data, '\n'), 0o644); err != nil {
+		log.Printf("%s: %v", file, err)
+	}
+}
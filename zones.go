@@ -0,0 +1,80 @@
+package main
+
+import "errors"
+
+// zoneLimits holds the zone capacities of the map being solved, or nil
+// when no zone has one. Like closedSlots it is set for every solve, and
+// schedulers honor it through the reservation table.
+var zoneLimits *zoneLimit
+
+var errZoneCapacity = errors.New("zone capacities are only supported by the dfs, bounded and flow solvers")
+
+// zoneLimit maps the rooms of every zone with a capacity, start and end
+// left out, to the index of their zone
+type zoneLimit struct {
+	zone     map[int]int
+	rooms    [][]int
+	names    []string
+	capacity []int
+}
+
+// newZoneLimit collects the zones of g that have a capacity, or returns
+// nil when there are none
+func newZoneLimit(g *Graph, start, end int) *zoneLimit {
+	var limits *zoneLimit
+	for _, zone := range g.zones {
+		if zone.Capacity <= 0 {
+			continue
+		}
+		if limits == nil {
+			limits = &zoneLimit{zone: make(map[int]int)}
+		}
+		i := len(limits.names)
+		limits.names = append(limits.names, zone.Name)
+		limits.capacity = append(limits.capacity, zone.Capacity)
+		limits.rooms = append(limits.rooms, nil)
+		for _, name := range zone.Rooms {
+			if room, ok := g.ID(name); ok && room != start && room != end {
+				limits.zone[room] = i
+				limits.rooms[i] = append(limits.rooms[i], room)
+			}
+		}
+	}
+	return limits
+}
+
+// of returns the index of the zone of room, if it is in one with a
+// capacity. It may be called on nil.
+func (l *zoneLimit) of(room int) (int, bool) {
+	if l == nil {
+		return 0, false
+	}
+	zone, ok := l.zone[room]
+	return zone, ok
+}
+
+// zoneCounter counts the ants in every zone per turn. A zone that is full
+// in a turn has all of its rooms reserved for that turn.
+type zoneCounter struct {
+	limits    *zoneLimit
+	occupants map[[2]int]int // by zone and turn
+}
+
+func newZoneCounter() *zoneCounter {
+	return &zoneCounter{limits: zoneLimits, occupants: make(map[[2]int]int)}
+}
+
+// occupy records an ant in room at the end of turn
+func (c *zoneCounter) occupy(table reservationTable, room, turn int) {
+	zone, ok := c.limits.of(room)
+	if !ok {
+		return
+	}
+	key := [2]int{zone, turn}
+	c.occupants[key]++
+	if c.occupants[key] >= c.limits.capacity[zone] {
+		for _, r := range c.limits.rooms[zone] {
+			table[roomSlot(r, turn)] = true
+		}
+	}
+}